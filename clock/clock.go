@@ -0,0 +1,88 @@
+package clock
+
+import "time"
+
+// Clock provides the subset of time package functionality
+// required for code that executes on a schedule.
+// Code using a Clock object can be tested with a Fake clock.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// Since returns the time elapsed since the specified time.
+	Since(t time.Time) time.Duration
+
+	// Sleep pauses the current goroutine for at least the specified duration.
+	Sleep(d time.Duration)
+
+	// After waits for the duration to elapse and then sends the current time on the returned channel.
+	After(d time.Duration) <-chan time.Time
+
+	// NewTicker returns a new Ticker that sends the time on its channel after each tick.
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker holds a channel that delivers ticks of a clock at intervals.
+type Ticker interface {
+	// C returns the channel on which the ticks are delivered.
+	C() <-chan time.Time
+
+	// Stop turns off the ticker.
+	Stop()
+}
+
+//////////////////////////////////////////////////////////////////////////
+
+var _ Clock = Real{}
+
+// Real is a Clock that calls through to the time package.
+type Real struct{}
+
+// Default is the Clock used when no other Clock is specified.
+var Default Clock = Real{}
+
+// Or returns the specified Clock or the Default clock if the specified clock is nil.
+func Or(clock Clock) Clock {
+	if clock == nil {
+		return Default
+	}
+	return clock
+}
+
+// Now implements the Clock interface.
+func (r Real) Now() time.Time {
+	return time.Now()
+}
+
+// Since implements the Clock interface.
+func (r Real) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+// Sleep implements the Clock interface.
+func (r Real) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+// After implements the Clock interface.
+func (r Real) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// NewTicker implements the Clock interface.
+func (r Real) NewTicker(d time.Duration) Ticker {
+	return &realTicker{ticker: time.NewTicker(d)}
+}
+
+// realTicker wraps a time.Ticker to implement the Ticker interface.
+type realTicker struct {
+	ticker *time.Ticker
+}
+
+func (rt *realTicker) C() <-chan time.Time {
+	return rt.ticker.C
+}
+
+func (rt *realTicker) Stop() {
+	rt.ticker.Stop()
+}
@@ -0,0 +1,37 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOr(t *testing.T) {
+	assert.Equal(t, Default, Or(nil))
+	fake := NewFake(time.Time{})
+	assert.Equal(t, fake, Or(fake))
+}
+
+func TestReal(t *testing.T) {
+	var clock Clock = Real{}
+	start := clock.Now()
+	clock.Sleep(5 * time.Millisecond)
+	assert.GreaterOrEqual(t, clock.Since(start), 5*time.Millisecond)
+	select {
+	case <-clock.After(5 * time.Millisecond):
+	case <-time.After(time.Second):
+		require.Fail(t, "After() never fired")
+	}
+	ticker := clock.NewTicker(2 * time.Millisecond)
+	require.NotNil(t, ticker)
+	defer ticker.Stop()
+	for i := 0; i < 3; i++ {
+		select {
+		case <-ticker.C():
+		case <-time.After(time.Second):
+			require.Fail(t, "Ticker never fired")
+		}
+	}
+}
@@ -0,0 +1,2 @@
+// Package clock provides an injectable clock abstraction with real and fake implementations.
+package clock
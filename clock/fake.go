@@ -0,0 +1,181 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+var _ Clock = &Fake{}
+
+// Fake is a Clock that only changes time when it is manually advanced.
+// Sleep, After and Ticker events are triggered as the Fake clock is advanced.
+// Use BlockUntil to wait for code under test to reach a Sleep, After, or NewTicker call
+// before advancing the clock.
+//
+// The zero value is not usable, construct Fake clocks with NewFake.
+type Fake struct {
+	lock    sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []*fakeWaiter
+}
+
+// fakeWaiter represents a pending After, Sleep, or Ticker event.
+type fakeWaiter struct {
+	when    time.Time
+	period  time.Duration
+	channel chan time.Time
+}
+
+// NewFake returns a new Fake clock set to the specified time.
+// If the specified time is zero an arbitrary fixed time is used.
+func NewFake(now time.Time) *Fake {
+	if now.IsZero() {
+		now = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+	f := &Fake{now: now}
+	f.cond = sync.NewCond(&f.lock)
+	return f
+}
+
+// Now implements the Clock interface.
+func (f *Fake) Now() time.Time {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.now
+}
+
+// Since implements the Clock interface.
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+// Sleep implements the Clock interface.
+// Blocks until the Fake clock has been advanced by the specified duration.
+func (f *Fake) Sleep(d time.Duration) {
+	<-f.After(d)
+}
+
+// After implements the Clock interface.
+// The channel receives the time when the Fake clock has been advanced by the specified duration.
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	waiter := &fakeWaiter{
+		when:    f.now.Add(d),
+		channel: make(chan time.Time, 1),
+	}
+	if d <= 0 {
+		waiter.channel <- f.now
+	} else {
+		f.addWaiter(waiter)
+	}
+	return waiter.channel
+}
+
+// NewTicker implements the Clock interface.
+// The ticker channel receives the time each time the Fake clock passes a tick.
+// As with time.Ticker, ticks are dropped if the receiver doesn't keep up.
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for Fake.NewTicker")
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	waiter := &fakeWaiter{
+		when:    f.now.Add(d),
+		period:  d,
+		channel: make(chan time.Time, 1),
+	}
+	f.addWaiter(waiter)
+	return &fakeTicker{fake: f, waiter: waiter}
+}
+
+// Advance the Fake clock by the specified duration,
+// triggering any events that come due in chronological order.
+func (f *Fake) Advance(d time.Duration) {
+	f.Set(f.Now().Add(d))
+}
+
+// Set the Fake clock to the specified time,
+// triggering any events that come due in chronological order.
+// Setting the time backwards does not trigger any events.
+func (f *Fake) Set(t time.Time) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for len(f.waiters) > 0 && !f.waiters[0].when.After(t) {
+		waiter := f.waiters[0]
+		f.waiters = f.waiters[1:]
+		f.now = waiter.when
+		select {
+		case waiter.channel <- waiter.when:
+		default:
+			// Ticks are dropped if the receiver doesn't keep up.
+		}
+		if waiter.period > 0 {
+			waiter.when = waiter.when.Add(waiter.period)
+			f.addWaiter(waiter)
+		}
+	}
+	f.now = t
+}
+
+// Waiters returns the number of pending Sleep, After and Ticker events.
+func (f *Fake) Waiters() int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return len(f.waiters)
+}
+
+// BlockUntil blocks until there are at least the specified number of
+// pending Sleep, After and Ticker events.
+func (f *Fake) BlockUntil(waiters int) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for len(f.waiters) < waiters {
+		f.cond.Wait()
+	}
+}
+
+// addWaiter adds the waiter to the list of waiters in chronological order.
+// Must be called with the lock held.
+func (f *Fake) addWaiter(waiter *fakeWaiter) {
+	index := sort.Search(len(f.waiters), func(i int) bool {
+		return f.waiters[i].when.After(waiter.when)
+	})
+	f.waiters = append(f.waiters, nil)
+	copy(f.waiters[index+1:], f.waiters[index:])
+	f.waiters[index] = waiter
+	f.cond.Broadcast()
+}
+
+// removeWaiter removes the waiter from the list of waiters.
+// Must be called with the lock held.
+func (f *Fake) removeWaiter(waiter *fakeWaiter) {
+	for i, w := range f.waiters {
+		if w == waiter {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			return
+		}
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////
+
+// fakeTicker implements the Ticker interface for the Fake clock.
+type fakeTicker struct {
+	fake   *Fake
+	waiter *fakeWaiter
+}
+
+func (ft *fakeTicker) C() <-chan time.Time {
+	return ft.waiter.channel
+}
+
+func (ft *fakeTicker) Stop() {
+	ft.fake.lock.Lock()
+	defer ft.fake.lock.Unlock()
+	ft.waiter.period = 0
+	ft.fake.removeWaiter(ft.waiter)
+}
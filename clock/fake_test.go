@@ -0,0 +1,105 @@
+package clock
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var start = time.Date(2020, time.March, 15, 12, 0, 0, 0, time.UTC)
+
+func ExampleFake() {
+	fake := NewFake(start)
+	done := make(chan bool)
+	go func() {
+		fake.Sleep(time.Hour)
+		fmt.Println("Awake at", fake.Now().Format(time.Kitchen))
+		done <- true
+	}()
+	fake.BlockUntil(1)
+	fake.Advance(time.Hour)
+	<-done
+	// Output: Awake at 1:00PM
+}
+
+func TestFake_Now(t *testing.T) {
+	fake := NewFake(start)
+	assert.Equal(t, start, fake.Now())
+	fake.Advance(time.Minute)
+	assert.Equal(t, start.Add(time.Minute), fake.Now())
+	assert.Equal(t, time.Minute, fake.Since(start))
+	fake.Set(start)
+	assert.Equal(t, start, fake.Now())
+	assert.False(t, NewFake(time.Time{}).Now().IsZero())
+}
+
+func TestFake_After(t *testing.T) {
+	fake := NewFake(start)
+	later := fake.After(2 * time.Second)
+	sooner := fake.After(time.Second)
+	assert.Equal(t, 2, fake.Waiters())
+	fake.Advance(500 * time.Millisecond)
+	assertNotReady(t, sooner)
+	assertNotReady(t, later)
+	fake.Advance(500 * time.Millisecond)
+	assert.Equal(t, start.Add(time.Second), <-sooner)
+	assertNotReady(t, later)
+	fake.Advance(5 * time.Second)
+	assert.Equal(t, start.Add(2*time.Second), <-later)
+	assert.Zero(t, fake.Waiters())
+	assert.Equal(t, start, <-NewFake(start).After(0))
+}
+
+func TestFake_Sleep(t *testing.T) {
+	fake := NewFake(start)
+	var wg sync.WaitGroup
+	awake := make([]bool, 3)
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			fake.Sleep(time.Duration(index+1) * time.Second)
+			awake[index] = true
+		}(i)
+	}
+	fake.BlockUntil(3)
+	fake.Advance(3 * time.Second)
+	wg.Wait()
+	assert.Equal(t, []bool{true, true, true}, awake)
+}
+
+func TestFake_Ticker(t *testing.T) {
+	fake := NewFake(start)
+	ticker := fake.NewTicker(time.Second)
+	require.NotNil(t, ticker)
+	assertNotReady(t, ticker.C())
+	for i := 1; i <= 3; i++ {
+		fake.Advance(time.Second)
+		assert.Equal(t, start.Add(time.Duration(i)*time.Second), <-ticker.C())
+	}
+
+	// Ticks are dropped if not received.
+	fake.Advance(5 * time.Second)
+	assert.Equal(t, start.Add(4*time.Second), <-ticker.C())
+	assertNotReady(t, ticker.C())
+
+	ticker.Stop()
+	assert.Zero(t, fake.Waiters())
+	fake.Advance(5 * time.Second)
+	assertNotReady(t, ticker.C())
+	assert.Panics(t, func() {
+		fake.NewTicker(0)
+	})
+}
+
+func assertNotReady(t *testing.T, channel <-chan time.Time) {
+	select {
+	case when := <-channel:
+		assert.Fail(t, "channel should not be ready", "received %s", when)
+	default:
+	}
+}
@@ -0,0 +1,319 @@
+package cycle
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/madkins23/go-utils/clock"
+)
+
+var errBadBurst = errors.New("burst must be positive")
+
+// RateLimiter implements a token bucket rate limiter.
+// Tokens are added to the bucket at a fixed rate up to a maximum burst size.
+// Each allowed event consumes a single token.
+// A RateLimiter is safe for concurrent use.
+type RateLimiter struct {
+	lock     sync.Mutex
+	clock    clock.Clock
+	interval time.Duration
+	burst    float64
+	tokens   float64
+	last     time.Time
+}
+
+// NewRateLimiter returns a new RateLimiter that adds one token every interval
+// up to the specified maximum burst of tokens.
+// The bucket starts full.
+func NewRateLimiter(interval time.Duration, burst uint) (*RateLimiter, error) {
+	if interval <= 0 {
+		return nil, errBadInterval
+	} else if burst < 1 {
+		return nil, errBadBurst
+	}
+	return &RateLimiter{
+		clock:    clock.Default,
+		interval: interval,
+		burst:    float64(burst),
+		tokens:   float64(burst),
+		last:     clock.Default.Now(),
+	}, nil
+}
+
+// SetClock configures the RateLimiter to use the specified clock.
+// The bucket is refilled.
+// Use a clock.Fake object to make tests faster and deterministic.
+func (rl *RateLimiter) SetClock(clk clock.Clock) {
+	rl.lock.Lock()
+	defer rl.lock.Unlock()
+	rl.clock = clock.Or(clk)
+	rl.tokens = rl.burst
+	rl.last = rl.clock.Now()
+}
+
+// Allow reports whether an event may happen now, consuming a token if so.
+func (rl *RateLimiter) Allow() bool {
+	rl.lock.Lock()
+	defer rl.lock.Unlock()
+	if rl.refill(); rl.tokens >= 1 {
+		rl.tokens--
+		return true
+	}
+	return false
+}
+
+// Tokens returns the number of tokens currently available.
+func (rl *RateLimiter) Tokens() float64 {
+	rl.lock.Lock()
+	defer rl.lock.Unlock()
+	rl.refill()
+	return rl.tokens
+}
+
+// Wait blocks until an event may happen, consuming a token,
+// or until the context is done in which case the context error is returned.
+// Waiting callers are not guaranteed to be served in order.
+func (rl *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		rl.lock.Lock()
+		rl.refill()
+		if rl.tokens >= 1 {
+			rl.tokens--
+			rl.lock.Unlock()
+			return nil
+		}
+		wait := time.Duration((1 - rl.tokens) * float64(rl.interval))
+		after := rl.clock.After(wait)
+		rl.lock.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-after:
+		}
+	}
+}
+
+// refill adds tokens to the bucket based on the time since the last refill.
+// Must be called with the lock held.
+func (rl *RateLimiter) refill() {
+	now := rl.clock.Now()
+	if elapsed := now.Sub(rl.last); elapsed > 0 {
+		rl.tokens += float64(elapsed) / float64(rl.interval)
+		if rl.tokens > rl.burst {
+			rl.tokens = rl.burst
+		}
+	}
+	rl.last = now
+}
+
+//////////////////////////////////////////////////////////////////////////
+
+// Debouncer executes a function once after a quiet period.
+// Each call to Trigger restarts the quiet period.
+// The function is executed in a separate goroutine and
+// never executes concurrently with itself.
+// A Debouncer is safe for concurrent use.
+type Debouncer struct {
+	lock    sync.Mutex
+	running sync.Mutex
+	clock   clock.Clock
+	ctx     context.Context
+	cancel  context.CancelFunc
+	quiet   time.Duration
+	fn      func()
+	last    time.Time
+	pending bool
+}
+
+// NewDebouncer returns a new Debouncer that executes the function
+// after the specified quiet period without any calls to Trigger.
+// Pending executions are dropped when the context is done or the Debouncer is stopped.
+func NewDebouncer(ctx context.Context, quiet time.Duration, fn func()) (*Debouncer, error) {
+	if fn == nil {
+		return nil, errNoCycleFn
+	} else if quiet <= 0 {
+		return nil, errBadInterval
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	return &Debouncer{
+		clock:  clock.Default,
+		ctx:    ctx,
+		cancel: cancel,
+		quiet:  quiet,
+		fn:     fn,
+	}, nil
+}
+
+// SetClock configures the Debouncer to use the specified clock.
+// Use a clock.Fake object to make tests faster and deterministic.
+// Must be called before Trigger.
+func (d *Debouncer) SetClock(clk clock.Clock) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.clock = clock.Or(clk)
+}
+
+// Trigger the Debouncer, restarting the quiet period.
+// Calls after the Debouncer has been stopped are ignored.
+func (d *Debouncer) Trigger() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.ctx.Err() != nil {
+		return
+	}
+	d.last = d.clock.Now()
+	if !d.pending {
+		d.pending = true
+		go d.wait()
+	}
+}
+
+// Pending returns true if an execution is waiting for the quiet period to end.
+func (d *Debouncer) Pending() bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.pending
+}
+
+// Stop the Debouncer, dropping any pending execution.
+func (d *Debouncer) Stop() {
+	d.cancel()
+}
+
+// wait is run as a goroutine until the quiet period ends or the Debouncer is stopped.
+func (d *Debouncer) wait() {
+	for {
+		d.lock.Lock()
+		remaining := d.quiet - d.clock.Since(d.last)
+		if remaining <= 0 {
+			d.pending = false
+			d.lock.Unlock()
+			d.running.Lock()
+			d.fn()
+			d.running.Unlock()
+			return
+		}
+		after := d.clock.After(remaining)
+		d.lock.Unlock()
+
+		select {
+		case <-d.ctx.Done():
+			d.lock.Lock()
+			d.pending = false
+			d.lock.Unlock()
+			return
+		case <-after:
+		}
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////
+
+// Throttler executes a function at most once per interval.
+// The first call to Trigger in an interval executes the function immediately.
+// If trailing execution is configured, calls during the interval
+// result in a single execution at the end of the interval.
+// The function never executes concurrently with itself.
+// A Throttler is safe for concurrent use.
+type Throttler struct {
+	lock     sync.Mutex
+	running  sync.Mutex
+	clock    clock.Clock
+	ctx      context.Context
+	cancel   context.CancelFunc
+	interval time.Duration
+	trailing bool
+	fn       func()
+	last     time.Time
+	ran      bool
+	pending  bool
+}
+
+// NewThrottler returns a new Throttler that executes the function
+// at most once per interval, optionally with a trailing execution.
+// Pending trailing executions are dropped when the context is done or the Throttler is stopped.
+func NewThrottler(ctx context.Context, interval time.Duration, trailing bool, fn func()) (*Throttler, error) {
+	if fn == nil {
+		return nil, errNoCycleFn
+	} else if interval <= 0 {
+		return nil, errBadInterval
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	return &Throttler{
+		clock:    clock.Default,
+		ctx:      ctx,
+		cancel:   cancel,
+		interval: interval,
+		trailing: trailing,
+		fn:       fn,
+	}, nil
+}
+
+// SetClock configures the Throttler to use the specified clock.
+// Use a clock.Fake object to make tests faster and deterministic.
+// Must be called before Trigger.
+func (t *Throttler) SetClock(clk clock.Clock) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.clock = clock.Or(clk)
+}
+
+// Trigger the Throttler.
+// If the function has not executed within the interval it is executed
+// immediately in the calling goroutine and true is returned.
+// Otherwise false is returned and a trailing execution may be scheduled.
+// Calls after the Throttler has been stopped are ignored.
+func (t *Throttler) Trigger() bool {
+	t.lock.Lock()
+	if t.ctx.Err() != nil {
+		t.lock.Unlock()
+		return false
+	}
+	now := t.clock.Now()
+	if !t.ran || now.Sub(t.last) >= t.interval {
+		if t.pending {
+			// Trailing execution hasn't happened yet, let it do the work.
+			t.lock.Unlock()
+			return false
+		}
+		t.ran = true
+		t.last = now
+		t.lock.Unlock()
+		t.running.Lock()
+		t.fn()
+		t.running.Unlock()
+		return true
+	}
+	if t.trailing && !t.pending {
+		t.pending = true
+		go t.trail(t.clock.After(t.interval - now.Sub(t.last)))
+	}
+	t.lock.Unlock()
+	return false
+}
+
+// Stop the Throttler, dropping any pending trailing execution.
+func (t *Throttler) Stop() {
+	t.cancel()
+}
+
+// trail is run as a goroutine to execute the function at the end of the interval.
+func (t *Throttler) trail(after <-chan time.Time) {
+	select {
+	case <-t.ctx.Done():
+		t.lock.Lock()
+		t.pending = false
+		t.lock.Unlock()
+	case <-after:
+		t.lock.Lock()
+		t.pending = false
+		t.last = t.clock.Now()
+		t.lock.Unlock()
+		t.running.Lock()
+		t.fn()
+		t.running.Unlock()
+	}
+}
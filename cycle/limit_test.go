@@ -0,0 +1,183 @@
+package cycle
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/madkins23/go-utils/clock"
+)
+
+func TestNewRateLimiter(t *testing.T) {
+	_, err := NewRateLimiter(0, 1)
+	assert.ErrorIs(t, err, errBadInterval)
+	_, err = NewRateLimiter(time.Second, 0)
+	assert.ErrorIs(t, err, errBadBurst)
+	rl, err := NewRateLimiter(time.Second, 1)
+	require.NoError(t, err)
+	assert.True(t, rl.Allow())
+}
+
+func TestRateLimiter_Allow(t *testing.T) {
+	fake := clock.NewFake(time.Time{})
+	rl, err := NewRateLimiter(time.Second, 3)
+	require.NoError(t, err)
+	rl.SetClock(fake)
+	assert.Equal(t, float64(3), rl.Tokens())
+	for i := 0; i < 3; i++ {
+		assert.True(t, rl.Allow())
+	}
+	assert.False(t, rl.Allow())
+	fake.Advance(500 * time.Millisecond)
+	assert.Equal(t, 0.5, rl.Tokens())
+	assert.False(t, rl.Allow())
+	fake.Advance(500 * time.Millisecond)
+	assert.True(t, rl.Allow())
+	assert.False(t, rl.Allow())
+	fake.Advance(time.Hour)
+	assert.Equal(t, float64(3), rl.Tokens(), "limited to burst")
+}
+
+func TestRateLimiter_Wait(t *testing.T) {
+	fake := clock.NewFake(time.Time{})
+	rl, err := NewRateLimiter(time.Second, 1)
+	require.NoError(t, err)
+	rl.SetClock(fake)
+	start := fake.Now()
+	require.NoError(t, rl.Wait(context.Background()))
+	result := make(chan error)
+	go func() {
+		result <- rl.Wait(context.Background())
+	}()
+	fake.BlockUntil(1)
+	fake.Advance(time.Second)
+	assert.NoError(t, <-result)
+	assert.Equal(t, time.Second, fake.Since(start))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		result <- rl.Wait(ctx)
+	}()
+	fake.BlockUntil(1)
+	cancel()
+	assert.ErrorIs(t, <-result, context.Canceled)
+}
+
+func TestNewDebouncer(t *testing.T) {
+	_, err := NewDebouncer(context.Background(), time.Second, nil)
+	assert.ErrorIs(t, err, errNoCycleFn)
+	_, err = NewDebouncer(context.Background(), 0, func() {})
+	assert.ErrorIs(t, err, errBadInterval)
+}
+
+func TestDebouncer(t *testing.T) {
+	fake := clock.NewFake(time.Time{})
+	ran := make(chan time.Time)
+	d, err := NewDebouncer(context.Background(), time.Second, func() {
+		ran <- fake.Now()
+	})
+	require.NoError(t, err)
+	d.SetClock(fake)
+	start := fake.Now()
+	assert.False(t, d.Pending())
+	d.Trigger()
+	assert.True(t, d.Pending())
+	for i := 0; i < 3; i++ {
+		fake.BlockUntil(1)
+		fake.Advance(500 * time.Millisecond)
+		d.Trigger()
+	}
+	fake.BlockUntil(1)
+	fake.Advance(500 * time.Millisecond)
+	// Quiet period restarted by the last trigger.
+	fake.BlockUntil(1)
+	fake.Advance(500 * time.Millisecond)
+	assert.Equal(t, 2500*time.Millisecond, (<-ran).Sub(start))
+	assert.Eventually(t, func() bool {
+		return !d.Pending()
+	}, time.Second, time.Millisecond)
+}
+
+func TestDebouncer_Stop(t *testing.T) {
+	fake := clock.NewFake(time.Time{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	d, err := NewDebouncer(ctx, time.Second, func() {
+		assert.Fail(t, "debounced function should not run")
+	})
+	require.NoError(t, err)
+	d.SetClock(fake)
+	d.Trigger()
+	fake.BlockUntil(1)
+	cancel()
+	assert.Eventually(t, func() bool {
+		return !d.Pending()
+	}, time.Second, time.Millisecond)
+	d.Trigger()
+	assert.False(t, d.Pending())
+	fake.Advance(time.Hour)
+	d.Stop()
+}
+
+func TestNewThrottler(t *testing.T) {
+	_, err := NewThrottler(context.Background(), time.Second, false, nil)
+	assert.ErrorIs(t, err, errNoCycleFn)
+	_, err = NewThrottler(context.Background(), 0, false, func() {})
+	assert.ErrorIs(t, err, errBadInterval)
+}
+
+func TestThrottler(t *testing.T) {
+	fake := clock.NewFake(time.Time{})
+	count := 0
+	th, err := NewThrottler(context.Background(), time.Second, false, func() {
+		count++
+	})
+	require.NoError(t, err)
+	th.SetClock(fake)
+	assert.True(t, th.Trigger())
+	assert.False(t, th.Trigger())
+	fake.Advance(999 * time.Millisecond)
+	assert.False(t, th.Trigger())
+	fake.Advance(time.Millisecond)
+	assert.True(t, th.Trigger())
+	assert.Equal(t, 2, count)
+	assert.Zero(t, fake.Waiters())
+	th.Stop()
+	fake.Advance(time.Hour)
+	assert.False(t, th.Trigger())
+	assert.Equal(t, 2, count)
+}
+
+func TestThrottler_Trailing(t *testing.T) {
+	fake := clock.NewFake(time.Time{})
+	var lock sync.Mutex
+	ran := make([]time.Duration, 0)
+	start := fake.Now()
+	th, err := NewThrottler(context.Background(), time.Second, true, func() {
+		lock.Lock()
+		ran = append(ran, fake.Since(start))
+		lock.Unlock()
+	})
+	require.NoError(t, err)
+	th.SetClock(fake)
+	assert.True(t, th.Trigger())
+	fake.Advance(200 * time.Millisecond)
+	assert.False(t, th.Trigger())
+	fake.Advance(200 * time.Millisecond)
+	assert.False(t, th.Trigger())
+	assert.Equal(t, 1, fake.Waiters(), "single trailing execution")
+	fake.Advance(600 * time.Millisecond)
+	assert.Eventually(t, func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(ran) == 2
+	}, time.Second, time.Millisecond)
+	assert.False(t, th.Trigger(), "trailing execution starts a new interval")
+	lock.Lock()
+	defer lock.Unlock()
+	assert.Equal(t, []time.Duration{0, time.Second}, ran)
+}
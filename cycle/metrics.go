@@ -0,0 +1,112 @@
+package cycle
+
+import (
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/madkins23/go-utils/log"
+)
+
+// CycleInfo describes a single execution of a Periodic cycle function.
+type CycleInfo struct {
+	// Cycle is the number of the cycle (starting at zero).
+	Cycle uint
+
+	// Scheduled is the time the cycle was scheduled to start.
+	Scheduled time.Time
+
+	// Started is the time the cycle actually started.
+	Started time.Time
+
+	// Late is how long after the scheduled time the cycle started.
+	Late time.Duration
+
+	// Duration of the cycle, only set for the after hook.
+	Duration time.Duration
+
+	// Err is the error returned by the cycle, only set for the after hook.
+	Err error
+}
+
+// HookFn is a function to be executed before or after each cycle.
+type HookFn func(info *CycleInfo)
+
+// PeriodicStats contains run statistics for a Periodic object.
+type PeriodicStats struct {
+	// Cycles is the number of completed cycles.
+	Cycles uint
+
+	// Errors is the number of errors returned by the cycle function.
+	// When a Retry object is configured each failed attempt is counted.
+	Errors uint
+
+	// Skipped is the number of scheduled cycles skipped because a cycle overran.
+	Skipped uint
+
+	// LastError is the most recent error returned by the cycle function.
+	LastError error
+
+	// LastStart is the time the most recent cycle started.
+	LastStart time.Time
+
+	// LastDuration is the duration of the most recent cycle.
+	LastDuration time.Duration
+
+	// MaxDuration is the longest duration of any cycle.
+	MaxDuration time.Duration
+
+	// TotalDuration is the total duration of all cycles.
+	TotalDuration time.Duration
+
+	// LastLate is how late the most recent cycle started relative to its schedule.
+	LastLate time.Duration
+
+	// MaxLate is the latest any cycle started relative to its schedule.
+	MaxLate time.Duration
+}
+
+// AverageDuration returns the average duration of all cycles.
+func (ps PeriodicStats) AverageDuration() time.Duration {
+	if ps.Cycles == 0 {
+		return 0
+	}
+	return ps.TotalDuration / time.Duration(ps.Cycles)
+}
+
+// record statistics for a completed cycle.
+func (ps *PeriodicStats) record(info *CycleInfo) {
+	ps.Cycles++
+	ps.LastStart = info.Started
+	ps.LastDuration = info.Duration
+	ps.TotalDuration += info.Duration
+	if info.Duration > ps.MaxDuration {
+		ps.MaxDuration = info.Duration
+	}
+	ps.LastLate = info.Late
+	if info.Late > ps.MaxLate {
+		ps.MaxLate = info.Late
+	}
+}
+
+// LogHook returns a HookFn that logs each cycle for use as an after hook.
+// Successful cycles are logged at debug level and failed cycles at error level.
+// If the specified logger is nil the default logger is used.
+func LogHook(logger *zerolog.Logger) HookFn {
+	return func(info *CycleInfo) {
+		l := logger
+		if l == nil {
+			l = log.Logger()
+		}
+		var event *zerolog.Event
+		if info.Err != nil {
+			event = l.Error().Err(info.Err)
+		} else {
+			event = l.Debug()
+		}
+		event.Uint("cycle", info.Cycle).
+			Dur("late", info.Late).
+			Dur("duration", info.Duration).
+			Msg("Periodic cycle")
+	}
+}
@@ -0,0 +1,192 @@
+package cycle
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/madkins23/go-utils/clock"
+)
+
+func TestPeriodicStats(t *testing.T) {
+	var stats PeriodicStats
+	assert.Zero(t, stats.AverageDuration())
+	stats.record(&CycleInfo{Duration: time.Second, Late: time.Millisecond})
+	stats.record(&CycleInfo{Duration: 3 * time.Second})
+	assert.Equal(t, uint(2), stats.Cycles)
+	assert.Equal(t, 2*time.Second, stats.AverageDuration())
+	assert.Equal(t, 3*time.Second, stats.MaxDuration)
+	assert.Equal(t, 3*time.Second, stats.LastDuration)
+	assert.Equal(t, time.Millisecond, stats.MaxLate)
+	assert.Zero(t, stats.LastLate)
+}
+
+func TestTickerHooks(t *testing.T) {
+	fake := clock.NewFake(time.Time{})
+	before := make([]CycleInfo, 0)
+	after := make([]CycleInfo, 0)
+	ran := make(chan bool)
+	p, err := NewPeriodic(func(cycles uint) error {
+		fake.Sleep(time.Duration(cycles+1) * time.Second)
+		ran <- true
+		if cycles == 2 {
+			return errTransient
+		}
+		return nil
+	}, nil, nil)
+	require.NoError(t, err)
+	p.SetClock(fake)
+	p.SetHooks(func(info *CycleInfo) {
+		before = append(before, *info)
+	}, func(info *CycleInfo) {
+		after = append(after, *info)
+	})
+	go p.Ticker(time.Minute)
+	fake.BlockUntil(1)
+	fake.Advance(time.Second)
+	<-ran
+	// The ticker is created after the initial cycle.
+	fake.BlockUntil(1)
+	fake.Advance(time.Minute)
+	fake.BlockUntil(2)
+	fake.Advance(2 * time.Second)
+	<-ran
+	fake.Advance(time.Minute - 2*time.Second)
+	fake.BlockUntil(2)
+	fake.Advance(3 * time.Second)
+	<-ran
+	assert.ErrorIs(t, p.Finished(), errTransient)
+
+	require.Len(t, before, 3)
+	require.Len(t, after, 3)
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, uint(i), before[i].Cycle)
+		assert.Equal(t, uint(i), after[i].Cycle)
+		assert.Zero(t, before[i].Duration)
+		assert.Equal(t, time.Duration(i+1)*time.Second, after[i].Duration)
+	}
+	assert.NoError(t, after[1].Err)
+	assert.ErrorIs(t, after[2].Err, errTransient)
+
+	stats := p.Stats()
+	assert.Equal(t, uint(3), stats.Cycles)
+	assert.Equal(t, uint(1), stats.Errors)
+	assert.ErrorIs(t, stats.LastError, errTransient)
+	assert.Equal(t, 3*time.Second, stats.MaxDuration)
+	assert.Equal(t, 6*time.Second, stats.TotalDuration)
+}
+
+func TestTickerSkipped(t *testing.T) {
+	fake := clock.NewFake(time.Time{})
+	ran := make(chan uint)
+	var p *Periodic
+	p, err := NewPeriodic(func(cycles uint) error {
+		ran <- cycles
+		switch cycles {
+		case 1:
+			// Overrun two and a half intervals.
+			fake.Sleep(150 * time.Second)
+		case 3:
+			p.Stop()
+		}
+		return nil
+	}, nil, nil)
+	require.NoError(t, err)
+	p.SetClock(fake)
+	go p.Ticker(time.Minute)
+	assert.Equal(t, uint(0), <-ran)
+	fake.BlockUntil(1)
+	fake.Advance(time.Minute)
+	assert.Equal(t, uint(1), <-ran)
+	fake.BlockUntil(2)
+	fake.Advance(150 * time.Second)
+	// Buffered tick at two minutes runs immediately, ticks at three minutes and later are dropped.
+	assert.Equal(t, uint(2), <-ran)
+	fake.Advance(30 * time.Second)
+	assert.Equal(t, uint(3), <-ran)
+	assert.NoError(t, p.Finished())
+
+	stats := p.Stats()
+	assert.Equal(t, uint(1), stats.Skipped)
+	assert.Equal(t, 150*time.Second, stats.MaxDuration)
+	assert.Equal(t, 90*time.Second, stats.MaxLate)
+}
+
+func TestTickerDriftCorrection(t *testing.T) {
+	fake := clock.NewFake(time.Time{})
+	start := fake.Now()
+	scheduled := make([]time.Duration, 0)
+	late := make([]time.Duration, 0)
+	ran := make(chan uint)
+	var p *Periodic
+	p, err := NewPeriodic(func(cycles uint) error {
+		ran <- cycles
+		switch cycles {
+		case 1:
+			fake.Sleep(10 * time.Second)
+		case 2:
+			// Overrun two and a half intervals.
+			fake.Sleep(150 * time.Second)
+		case 3:
+			p.Stop()
+		}
+		return nil
+	}, nil, nil)
+	require.NoError(t, err)
+	p.SetClock(fake)
+	p.SetDriftCorrection(true)
+	p.SetHooks(func(info *CycleInfo) {
+		scheduled = append(scheduled, info.Scheduled.Sub(start))
+		late = append(late, info.Late)
+	}, nil)
+	go p.Ticker(time.Minute)
+	assert.Equal(t, uint(0), <-ran)
+
+	fake.BlockUntil(1)
+	fake.Advance(time.Minute)
+	assert.Equal(t, uint(1), <-ran)
+	fake.BlockUntil(1)
+	fake.Advance(10 * time.Second)
+
+	// Schedule is not affected by cycle duration.
+	fake.BlockUntil(1)
+	fake.Advance(50 * time.Second)
+	assert.Equal(t, uint(2), <-ran)
+	fake.BlockUntil(1)
+	fake.Advance(150 * time.Second)
+
+	// Skip over scheduled start at three minutes,
+	// start immediately for four minutes.
+	assert.Equal(t, uint(3), <-ran)
+	assert.NoError(t, p.Finished())
+
+	assert.Equal(t, []time.Duration{0, time.Minute, 2 * time.Minute, 4 * time.Minute}, scheduled)
+	assert.Equal(t, []time.Duration{0, 0, 0, 30 * time.Second}, late)
+	assert.Equal(t, uint(1), p.Stats().Skipped)
+}
+
+func TestLogHook(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := zerolog.New(&buffer).Level(zerolog.DebugLevel)
+	hook := LogHook(&logger)
+	hook(&CycleInfo{Cycle: 3, Late: time.Millisecond, Duration: time.Second})
+	hook(&CycleInfo{Cycle: 4, Err: errors.New("failure")})
+
+	lines := bytes.Split(bytes.TrimSpace(buffer.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(lines[0], &record))
+	assert.Equal(t, "debug", record["level"])
+	assert.Equal(t, float64(3), record["cycle"])
+	assert.Equal(t, float64(1000), record["duration"])
+	require.NoError(t, json.Unmarshal(lines[1], &record))
+	assert.Equal(t, "error", record["level"])
+	assert.Equal(t, "failure", record["error"])
+	assert.NotNil(t, LogHook(nil))
+}
@@ -1,11 +1,15 @@
 package cycle
 
 import (
+	"context"
 	"errors"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
+
+	"github.com/madkins23/go-utils/clock"
 )
 
 // PeriodicFn is a function to be executed periodically.
@@ -26,6 +30,15 @@ type Periodic struct {
 	cycleFn  PeriodicFn
 	finalFn  FinalFn
 	signalFn SignalFn
+	retry    *Retry
+	clock    clock.Clock
+	ctx      context.Context
+	cancel   context.CancelFunc
+	beforeFn HookFn
+	afterFn  HookFn
+	drift    bool
+	lock     sync.Mutex
+	stats    PeriodicStats
 	endErr   error
 }
 
@@ -38,18 +51,120 @@ func NewPeriodic(cycleFn PeriodicFn, finalFn FinalFn, signalFn SignalFn) (*Perio
 		return nil, errNoCycleFn
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
 	p := &Periodic{
+		ctx:      ctx,
+		cancel:   cancel,
 		stop:     make(chan bool, 3),
 		done:     make(chan bool, 2),
 		signals:  make(chan os.Signal),
 		cycleFn:  cycleFn,
 		finalFn:  finalFn,
 		signalFn: signalFn,
+		clock:    clock.Default,
 	}
 
 	return p, nil
 }
 
+// SetClock configures the Periodic object to use the specified clock.
+// Use a clock.Fake object to make tests faster and deterministic.
+// Must be called before Ticker.
+func (p *Periodic) SetClock(clk clock.Clock) {
+	p.clock = clock.Or(clk)
+}
+
+// SetRetry configures the Periodic object to retry a failing cycle function.
+// Without a Retry object any error returned by the cycle function terminates Ticker.
+// With a Retry object the cycle function is retried according to its policy and
+// Ticker only terminates if the retries are exhausted.
+// Stopping the Periodic object interrupts any delay between retries.
+// Must be called before Ticker.
+func (p *Periodic) SetRetry(retry *Retry) {
+	p.retry = retry
+}
+
+// SetHooks configures functions to be called before and after each cycle.
+// Either function may be nil.
+// Must be called before Ticker.
+func (p *Periodic) SetHooks(before, after HookFn) {
+	p.beforeFn = before
+	p.afterFn = after
+}
+
+// SetDriftCorrection configures the Periodic object to schedule cycles
+// at fixed intervals from the start of the initial cycle.
+// Each cycle is started on schedule regardless of how long previous cycles took.
+// If a cycle runs past one or more scheduled starts those starts are skipped.
+// Without drift correction cycles are scheduled by a clock.Ticker
+// which may drift relative to the start of the initial cycle.
+// Must be called before Ticker.
+func (p *Periodic) SetDriftCorrection(drift bool) {
+	p.drift = drift
+}
+
+// execute a single cycle, calling hooks and recording statistics.
+func (p *Periodic) execute(cycles uint, scheduled time.Time) error {
+	info := &CycleInfo{
+		Cycle:     cycles,
+		Scheduled: scheduled,
+		Started:   p.clock.Now(),
+	}
+	if info.Started.After(scheduled) {
+		info.Late = info.Started.Sub(scheduled)
+	}
+	if p.beforeFn != nil {
+		p.beforeFn(info)
+	}
+
+	info.Err = p.runCycle(cycles)
+	info.Duration = p.clock.Since(info.Started)
+
+	p.lock.Lock()
+	p.stats.record(info)
+	p.lock.Unlock()
+
+	if p.afterFn != nil {
+		p.afterFn(info)
+	}
+	return info.Err
+}
+
+// runCycle executes the cycle function, retrying it if so configured.
+func (p *Periodic) runCycle(cycles uint) error {
+	if p.retry == nil {
+		return p.attempt(cycles)
+	}
+	retry := *p.retry
+	if retry.Clock == nil {
+		retry.Clock = p.clock
+	}
+	return retry.Do(p.ctx, func(_ uint) error {
+		return p.attempt(cycles)
+	})
+}
+
+// attempt executes the cycle function once, counting any error.
+func (p *Periodic) attempt(cycles uint) error {
+	err := p.cycleFn(cycles)
+	if err != nil {
+		p.lock.Lock()
+		p.stats.Errors++
+		p.stats.LastError = err
+		p.lock.Unlock()
+	}
+	return err
+}
+
+// skip records skipped cycles.
+func (p *Periodic) skip(skipped uint) {
+	if skipped > 0 {
+		p.lock.Lock()
+		p.stats.Skipped += skipped
+		p.lock.Unlock()
+	}
+}
+
 // handleSignals is run as a goroutine to handle termination signals (e.g. <ctrl>-C).
 func (p *Periodic) handleSignals() {
 	if sig, ok := <-p.signals; ok {
@@ -64,6 +179,7 @@ func (p *Periodic) handleSignals() {
 // Uses a ticker to start the code at known intervals.
 // If the code runs longer than a ticker interval some intervals will be skipped.
 // The code is run initially, then the ticker is started.
+// If drift correction is configured a timer is used for each cycle instead of a ticker.
 // Run in a goroutine or this method will block until completion.
 func (p *Periodic) Ticker(interval time.Duration) {
 	if p.finalFn != nil {
@@ -71,7 +187,8 @@ func (p *Periodic) Ticker(interval time.Duration) {
 	}
 
 	cycles := uint(0)
-	if p.endErr = p.cycleFn(cycles); p.endErr != nil {
+	scheduled := p.clock.Now()
+	if p.endErr = p.execute(cycles, scheduled); p.endErr != nil {
 		p.done <- true
 		return
 	}
@@ -79,31 +196,63 @@ func (p *Periodic) Ticker(interval time.Duration) {
 	signal.Notify(p.signals, syscall.SIGINT, syscall.SIGTERM)
 	go p.handleSignals()
 
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
+	var ticker clock.Ticker
+	if !p.drift {
+		ticker = p.clock.NewTicker(interval)
+		defer ticker.Stop()
+	}
 	for {
 		// Use two select statements to prioritize stop channel over ticker.
 		select {
 		case <-p.stop:
+			signal.Stop(p.signals)
 			close(p.signals)
 			p.done <- true
-			ticker.Stop()
 			return
 		default:
 		}
-		select {
-		case <-ticker.C:
-			cycles++
+		if p.drift {
+			scheduled = p.nextScheduled(scheduled, interval)
+			<-p.clock.After(scheduled.Sub(p.clock.Now()))
+		} else {
+			tick := <-ticker.C()
+			// Round to the nearest interval as the initial cycle precedes the ticker.
+			if missed := (tick.Sub(scheduled) + interval/2) / interval; missed > 1 {
+				p.skip(uint(missed - 1))
+			}
+			scheduled = tick
 		}
-		if p.endErr = p.cycleFn(cycles); p.endErr != nil {
+		cycles++
+		if p.endErr = p.execute(cycles, scheduled); p.endErr != nil {
 			p.stop <- true
 			continue
 		}
 	}
 }
 
+// nextScheduled returns the next scheduled start time for drift correction mode.
+// Any scheduled start times that have already passed are skipped.
+func (p *Periodic) nextScheduled(previous time.Time, interval time.Duration) time.Time {
+	next := previous.Add(interval)
+	if late := p.clock.Since(next); late > 0 {
+		missed := late / interval
+		p.skip(uint(missed))
+		next = next.Add(missed * interval)
+	}
+	return next
+}
+
+// Stats returns a snapshot of the run statistics for the Periodic object.
+// May be called at any time from any goroutine.
+func (p *Periodic) Stats() PeriodicStats {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.stats
+}
+
 // Stop periodic cycling.
 func (p *Periodic) Stop() {
+	p.cancel()
 	p.stop <- true
 }
 
@@ -10,6 +10,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/madkins23/go-utils/clock"
 )
 
 func ExamplePeriodic() {
@@ -165,3 +167,58 @@ func TestTickerLongCycle(t *testing.T) {
 	assert.True(t, started)
 	assert.True(t, stopped)
 }
+
+func TestTickerFakeClock(t *testing.T) {
+	fake := clock.NewFake(time.Time{})
+	ran := make(chan uint, 10)
+	p, err := NewPeriodic(func(cycles uint) error {
+		ran <- cycles
+		return nil
+	}, nil, nil)
+	require.NoError(t, err)
+	p.SetClock(fake)
+	go p.Ticker(time.Minute)
+	assert.Equal(t, uint(0), <-ran)
+	fake.BlockUntil(1)
+	for i := uint(1); i <= 5; i++ {
+		fake.Advance(time.Minute)
+		assert.Equal(t, i, <-ran)
+	}
+	// Stop may take effect after the next tick and cycle.
+	p.Stop()
+	fake.Advance(time.Minute)
+	assert.NoError(t, p.Finished())
+	assert.LessOrEqual(t, len(ran), 1)
+	assert.Zero(t, fake.Waiters())
+}
+
+func TestTickerFakeClockLongCycle(t *testing.T) {
+	fake := clock.NewFake(time.Time{})
+	ran := make(chan uint)
+	p, err := NewPeriodic(func(cycles uint) error {
+		ran <- cycles
+		// Each cycle takes two and a half intervals.
+		fake.Sleep(150 * time.Second)
+		return nil
+	}, nil, nil)
+	require.NoError(t, err)
+	p.SetClock(fake)
+	go p.Ticker(time.Minute)
+	assert.Equal(t, uint(0), <-ran)
+	fake.BlockUntil(1)
+	fake.Advance(150 * time.Second)
+	// The ticker is created after the initial cycle.
+	fake.BlockUntil(1)
+	fake.Advance(time.Minute)
+	for i := uint(1); i <= 3; i++ {
+		// Ticks during each long cycle are dropped so the cycle count increments by one.
+		assert.Equal(t, i, <-ran)
+		fake.BlockUntil(2)
+		fake.Advance(150 * time.Second)
+	}
+	assert.Equal(t, uint(4), <-ran)
+	p.Stop()
+	fake.BlockUntil(2)
+	fake.Advance(150 * time.Second)
+	assert.NoError(t, p.Finished())
+}
@@ -0,0 +1,187 @@
+package cycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/madkins23/go-utils/clock"
+)
+
+// BackoffPolicy determines the delay before each retry attempt.
+type BackoffPolicy interface {
+	// Backoff returns the delay before the specified attempt (starting at one).
+	// The previous delay is provided for policies that depend on it
+	// and will be zero before the first retry.
+	Backoff(attempt uint, previous time.Duration) time.Duration
+}
+
+// ConstantBackoff waits the same amount of time before each retry.
+type ConstantBackoff struct {
+	Delay time.Duration
+}
+
+// Backoff implements the BackoffPolicy interface.
+func (cb ConstantBackoff) Backoff(_ uint, _ time.Duration) time.Duration {
+	return cb.Delay
+}
+
+// ExponentialBackoff multiplies the delay before each retry.
+type ExponentialBackoff struct {
+	// Initial delay before the first retry.
+	Initial time.Duration
+
+	// Multiplier applied to the delay for each subsequent retry.
+	// Defaults to 2 if less than or equal to 1.
+	Multiplier float64
+
+	// Max is the maximum delay, ignored if zero.
+	// Without a maximum the delay stops growing at the largest time.Duration.
+	Max time.Duration
+}
+
+// maxDuration is the largest time.Duration.
+const maxDuration = time.Duration(math.MaxInt64)
+
+// Backoff implements the BackoffPolicy interface.
+func (eb ExponentialBackoff) Backoff(_ uint, previous time.Duration) time.Duration {
+	delay := eb.Initial
+	if previous > 0 {
+		multiplier := eb.Multiplier
+		if multiplier <= 1 {
+			multiplier = 2
+		}
+		// Converting a float64 too large for time.Duration does not produce a usable value.
+		if next := float64(previous) * multiplier; next >= float64(maxDuration) {
+			delay = maxDuration
+		} else {
+			delay = time.Duration(next)
+		}
+	}
+	if eb.Max > 0 && delay > eb.Max {
+		delay = eb.Max
+	}
+	return delay
+}
+
+// DecorrelatedJitter randomizes the delay before each retry
+// between Base and three times the previous delay, capped at Max.
+// See https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/
+type DecorrelatedJitter struct {
+	Base time.Duration
+	Max  time.Duration
+}
+
+// Backoff implements the BackoffPolicy interface.
+func (dj DecorrelatedJitter) Backoff(_ uint, previous time.Duration) time.Duration {
+	if previous < dj.Base {
+		previous = dj.Base
+	}
+	if previous > maxDuration/3 {
+		previous = maxDuration / 3
+	}
+	delay := dj.Base
+	if spread := int64(previous)*3 - int64(dj.Base); spread > 0 {
+		delay += time.Duration(rand.Int63n(spread))
+	}
+	if dj.Max > 0 && delay > dj.Max {
+		delay = dj.Max
+	}
+	return delay
+}
+
+//////////////////////////////////////////////////////////////////////////
+
+// RetryFn is a function to be retried.
+// The attempt number starts at one.
+type RetryFn func(attempt uint) error
+
+// Retry executes a function repeatedly until it succeeds or a limit is reached.
+// At least one of MaxAttempts or MaxElapsed should be set
+// or the function will be retried until it succeeds or the context is done.
+type Retry struct {
+	// Policy determines the delay between attempts.
+	// If nil there is no delay.
+	Policy BackoffPolicy
+
+	// MaxAttempts is the maximum number of attempts, ignored if zero.
+	MaxAttempts uint
+
+	// MaxElapsed is the maximum time to spend on attempts, ignored if zero.
+	// No attempt will be started after this time has elapsed.
+	MaxElapsed time.Duration
+
+	// Clock used to measure elapsed time and delays.
+	// If nil the clock.Default object is used.
+	Clock clock.Clock
+}
+
+// ErrRetryExhausted is joined with the attempt errors when retries are exhausted.
+var ErrRetryExhausted = errors.New("retries exhausted")
+
+// Do executes the specified function until it returns no error or retries are exhausted.
+// A nil return signifies success.
+// Otherwise the error returned contains all attempt errors via errors.Join()
+// along with ErrRetryExhausted, any Permanent error, or the context error.
+func (r *Retry) Do(ctx context.Context, fn RetryFn) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	clk := clock.Or(r.Clock)
+	start := clk.Now()
+	errs := make([]error, 0)
+	delay := time.Duration(0)
+	for attempt := uint(1); ; attempt++ {
+		err := fn(attempt)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("attempt %d: %w", attempt, err))
+
+		var perm *permanent
+		if errors.As(err, &perm) {
+			return errors.Join(errs...)
+		} else if r.MaxAttempts > 0 && attempt >= r.MaxAttempts {
+			return errors.Join(append(errs, ErrRetryExhausted)...)
+		}
+
+		if r.Policy != nil {
+			delay = r.Policy.Backoff(attempt, delay)
+		}
+		if r.MaxElapsed > 0 && clk.Since(start)+delay >= r.MaxElapsed {
+			return errors.Join(append(errs, ErrRetryExhausted)...)
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Join(append(errs, ctx.Err())...)
+		case <-clk.After(delay):
+		}
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////
+
+// permanent marks an error that should not be retried.
+type permanent struct {
+	err error
+}
+
+func (p *permanent) Error() string {
+	return p.err.Error()
+}
+
+func (p *permanent) Unwrap() error {
+	return p.err
+}
+
+// Permanent wraps an error to prevent any further retry attempts.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanent{err: err}
+}
@@ -0,0 +1,206 @@
+package cycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/madkins23/go-utils/clock"
+)
+
+var errTransient = errors.New("transient")
+
+func ExampleRetry() {
+	retry := &Retry{
+		Policy:      ExponentialBackoff{Initial: time.Millisecond, Max: 5 * time.Millisecond},
+		MaxAttempts: 5,
+	}
+	err := retry.Do(context.Background(), func(attempt uint) error {
+		fmt.Println("Attempt", attempt)
+		if attempt < 3 {
+			return errTransient
+		}
+		return nil
+	})
+	fmt.Println(err)
+	// Output: Attempt 1
+	// Attempt 2
+	// Attempt 3
+	// <nil>
+}
+
+func TestConstantBackoff(t *testing.T) {
+	policy := ConstantBackoff{Delay: time.Second}
+	assert.Equal(t, time.Second, policy.Backoff(1, 0))
+	assert.Equal(t, time.Second, policy.Backoff(7, time.Second))
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	policy := ExponentialBackoff{Initial: time.Second, Max: 10 * time.Second}
+	delay := time.Duration(0)
+	expected := []time.Duration{1, 2, 4, 8, 10, 10}
+	for i, exp := range expected {
+		delay = policy.Backoff(uint(i+1), delay)
+		assert.Equal(t, exp*time.Second, delay)
+	}
+	policy.Multiplier = 1.5
+	assert.Equal(t, 3*time.Second, policy.Backoff(2, 2*time.Second))
+}
+
+func TestExponentialBackoff_overflow(t *testing.T) {
+	policy := ExponentialBackoff{Initial: time.Second}
+	delay := time.Duration(0)
+	for i := 1; i < 100; i++ {
+		previous := delay
+		delay = policy.Backoff(uint(i), delay)
+		assert.GreaterOrEqual(t, delay, previous)
+	}
+	assert.Equal(t, time.Duration(math.MaxInt64), delay)
+	policy.Max = time.Hour
+	assert.Equal(t, time.Hour, policy.Backoff(2, time.Duration(math.MaxInt64)))
+}
+
+func TestDecorrelatedJitter(t *testing.T) {
+	policy := DecorrelatedJitter{Base: time.Second, Max: 10 * time.Second}
+	delay := time.Duration(0)
+	for i := 1; i < 100; i++ {
+		previous := delay
+		if previous < policy.Base {
+			previous = policy.Base
+		}
+		delay = policy.Backoff(uint(i), delay)
+		assert.GreaterOrEqual(t, delay, policy.Base)
+		assert.LessOrEqual(t, delay, policy.Max)
+		assert.LessOrEqual(t, delay, 3*previous)
+	}
+	policy.Max = 0
+	assert.Greater(t, policy.Backoff(2, time.Duration(math.MaxInt64)), time.Duration(0))
+}
+
+func TestRetry_MaxAttempts(t *testing.T) {
+	count := uint(0)
+	retry := &Retry{MaxAttempts: 3}
+	err := retry.Do(context.Background(), func(attempt uint) error {
+		count++
+		assert.Equal(t, count, attempt)
+		return fmt.Errorf("failure %d", attempt)
+	})
+	require.Error(t, err)
+	assert.Equal(t, uint(3), count)
+	assert.ErrorIs(t, err, ErrRetryExhausted)
+	assert.Equal(t,
+		"attempt 1: failure 1\nattempt 2: failure 2\nattempt 3: failure 3\nretries exhausted",
+		err.Error())
+}
+
+func TestRetry_MaxElapsed(t *testing.T) {
+	count := 0
+	retry := &Retry{
+		Policy:     ConstantBackoff{Delay: 10 * time.Millisecond},
+		MaxElapsed: 25 * time.Millisecond,
+	}
+	start := time.Now()
+	err := retry.Do(context.Background(), func(attempt uint) error {
+		count++
+		return errTransient
+	})
+	assert.ErrorIs(t, err, errTransient)
+	assert.ErrorIs(t, err, ErrRetryExhausted)
+	assert.Equal(t, 3, count)
+	assert.Less(t, time.Since(start), 25*time.Millisecond)
+}
+
+func TestRetry_Permanent(t *testing.T) {
+	count := 0
+	retry := &Retry{MaxAttempts: 5}
+	err := retry.Do(context.Background(), func(attempt uint) error {
+		count++
+		if attempt == 2 {
+			return Permanent(errTransient)
+		}
+		return errors.New("again")
+	})
+	assert.Equal(t, 2, count)
+	assert.ErrorIs(t, err, errTransient)
+	assert.NotErrorIs(t, err, ErrRetryExhausted)
+	assert.Nil(t, Permanent(nil))
+}
+
+func TestRetry_Context(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Millisecond)
+	defer cancel()
+	retry := &Retry{Policy: ConstantBackoff{Delay: 10 * time.Millisecond}}
+	err := retry.Do(ctx, func(attempt uint) error {
+		return errTransient
+	})
+	assert.ErrorIs(t, err, errTransient)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestTickerRetry(t *testing.T) {
+	failures := 0
+	count := 0
+	p, err := NewPeriodic(func(cycles uint) error {
+		if cycles == 2 && failures < 2 {
+			failures++
+			return errTransient
+		}
+		count++
+		return nil
+	}, nil, nil)
+	require.NoError(t, err)
+	p.SetRetry(&Retry{Policy: ConstantBackoff{Delay: time.Millisecond}, MaxAttempts: 3})
+	go p.Ticker(5 * time.Millisecond)
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		p.Stop()
+	}()
+	assert.NoError(t, p.Finished())
+	assert.Equal(t, 2, failures)
+	assert.Greater(t, count, 3)
+}
+
+func TestTickerRetryExhausted(t *testing.T) {
+	p, err := NewPeriodic(func(cycles uint) error {
+		if cycles == 1 {
+			return errTransient
+		}
+		return nil
+	}, nil, nil)
+	require.NoError(t, err)
+	p.SetRetry(&Retry{MaxAttempts: 3})
+	go p.Ticker(5 * time.Millisecond)
+	err = p.Finished()
+	assert.ErrorIs(t, err, errTransient)
+	assert.ErrorIs(t, err, ErrRetryExhausted)
+}
+
+func TestRetry_FakeClock(t *testing.T) {
+	fake := clock.NewFake(time.Time{})
+	start := fake.Now()
+	attempts := make([]time.Duration, 0)
+	retry := &Retry{
+		Policy:      ExponentialBackoff{Initial: time.Second, Max: 5 * time.Second},
+		MaxAttempts: 5,
+		Clock:       fake,
+	}
+	result := make(chan error)
+	go func() {
+		result <- retry.Do(context.Background(), func(attempt uint) error {
+			attempts = append(attempts, fake.Since(start))
+			return errTransient
+		})
+	}()
+	for _, delay := range []time.Duration{1, 2, 4, 5} {
+		fake.BlockUntil(1)
+		fake.Advance(delay * time.Second)
+	}
+	assert.ErrorIs(t, <-result, ErrRetryExhausted)
+	assert.Equal(t, []time.Duration{0, time.Second, 3 * time.Second, 7 * time.Second, 12 * time.Second}, attempts)
+}
@@ -0,0 +1,320 @@
+package cycle
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/madkins23/go-utils/clock"
+)
+
+// OverlapPolicy specifies what a Scheduler does when a job is due
+// while a previous execution of the same job is still running.
+type OverlapPolicy uint8
+
+const (
+	// OverlapSkip skips the new execution (the default).
+	OverlapSkip OverlapPolicy = iota
+
+	// OverlapQueue runs the new execution after the current one completes.
+	// Executions of the job never overlap but none are lost.
+	OverlapQueue
+
+	// OverlapConcurrent starts the new execution immediately.
+	OverlapConcurrent
+)
+
+// JobStats contains run statistics for a Scheduler job.
+type JobStats struct {
+	// Runs is the number of completed executions of the job.
+	Runs uint
+
+	// Errors is the number of executions that returned an error.
+	Errors uint
+
+	// Skipped is the number of executions skipped due to OverlapSkip.
+	Skipped uint
+
+	// Running is the number of executions currently in flight.
+	Running uint
+
+	// Queued is the number of executions waiting due to OverlapQueue.
+	Queued uint
+
+	// LastRun is the time the most recently completed execution started.
+	LastRun time.Time
+
+	// LastDuration is the duration of the most recently completed execution.
+	LastDuration time.Duration
+
+	// LastError is the error returned by the most recent failed execution.
+	LastError error
+}
+
+// Scheduler executes multiple named jobs, each on its own interval.
+// A single signal handler and stop path is shared by all jobs.
+// Errors returned by a job are counted but do not stop the job or the Scheduler.
+type Scheduler struct {
+	lock     sync.Mutex
+	jobs     map[string]*job
+	running  sync.WaitGroup
+	tickers  sync.WaitGroup
+	stop     chan bool
+	done     chan bool
+	signals  chan os.Signal
+	signalFn SignalFn
+	clock    clock.Clock
+	started  bool
+	stopped  bool
+}
+
+// job is a single named job managed by a Scheduler.
+type job struct {
+	name     string
+	interval time.Duration
+	cycleFn  PeriodicFn
+	overlap  OverlapPolicy
+	cycles   uint
+	stats    JobStats
+}
+
+var (
+	errJobExists      = errors.New("job already exists")
+	errBadInterval    = errors.New("interval must be positive")
+	errSchedulerState = errors.New("scheduler already started")
+)
+
+// NewScheduler returns a new Scheduler object.
+// The signalFn argument may be nil.
+func NewScheduler(signalFn SignalFn) *Scheduler {
+	return &Scheduler{
+		jobs:     make(map[string]*job),
+		stop:     make(chan bool),
+		done:     make(chan bool),
+		signals:  make(chan os.Signal, 1),
+		signalFn: signalFn,
+		clock:    clock.Default,
+	}
+}
+
+// SetClock configures the Scheduler to use the specified clock.
+// Use a clock.Fake object to make tests faster and deterministic.
+// Must be called before Start.
+func (s *Scheduler) SetClock(clk clock.Clock) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.clock = clock.Or(clk)
+}
+
+// Add a named job to the Scheduler.
+// The cycleFn is called with the number of the cycle (starting at zero).
+// Jobs must be added before the Scheduler is started.
+func (s *Scheduler) Add(name string, interval time.Duration, cycleFn PeriodicFn, overlap OverlapPolicy) error {
+	if cycleFn == nil {
+		return errNoCycleFn
+	} else if interval <= 0 {
+		return fmt.Errorf("job '%s': %w", name, errBadInterval)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.started {
+		return errSchedulerState
+	} else if _, found := s.jobs[name]; found {
+		return fmt.Errorf("job '%s': %w", name, errJobExists)
+	}
+
+	s.jobs[name] = &job{
+		name:     name,
+		interval: interval,
+		cycleFn:  cycleFn,
+		overlap:  overlap,
+	}
+	return nil
+}
+
+// Names returns the sorted names of all jobs.
+func (s *Scheduler) Names() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	names := make([]string, 0, len(s.jobs))
+	for name := range s.jobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Stats returns a snapshot of the run statistics for the named job.
+func (s *Scheduler) Stats(name string) (JobStats, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if j, found := s.jobs[name]; found {
+		return j.stats, true
+	}
+	return JobStats{}, false
+}
+
+// Start all jobs.
+// Each job is run immediately and then on its own interval.
+// Does not block, use Finished() to wait for the Scheduler to stop.
+func (s *Scheduler) Start() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.started {
+		return errSchedulerState
+	}
+	s.started = true
+
+	signal.Notify(s.signals, syscall.SIGINT, syscall.SIGTERM)
+	go s.handleSignals()
+
+	for _, j := range s.jobs {
+		s.tickers.Add(1)
+		go s.ticker(j)
+	}
+
+	return nil
+}
+
+// Stop all jobs and wait for in-flight executions to complete.
+// Queued executions are discarded.
+// Calling Stop more than once is harmless.
+//
+// Stop must not be called from within a job since it would wait for the job itself
+// to complete and never return, use RequestStop instead.
+func (s *Scheduler) Stop() {
+	s.RequestStop()
+	<-s.done
+}
+
+// RequestStop stops all jobs without waiting for in-flight executions to complete.
+// Queued executions are discarded.
+// Unlike Stop it may be called from within a job (e.g. on a fatal condition).
+// Use Finished() to wait for in-flight executions to complete.
+// Calling RequestStop more than once is harmless.
+func (s *Scheduler) RequestStop() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.stopped {
+		return
+	}
+	s.stopped = true
+
+	signal.Stop(s.signals)
+	close(s.stop)
+	go func() {
+		s.tickers.Wait()
+		s.running.Wait()
+		close(s.done)
+	}()
+}
+
+// Finished waits for the Scheduler to be stopped, either by Stop() or a signal.
+// Does not call Stop().
+func (s *Scheduler) Finished() {
+	<-s.done
+}
+
+// handleSignals is run as a goroutine to handle termination signals (e.g. <ctrl>-C).
+func (s *Scheduler) handleSignals() {
+	select {
+	case sig := <-s.signals:
+		if s.signalFn != nil {
+			s.signalFn(sig)
+		}
+		s.Stop()
+	case <-s.stop:
+	}
+}
+
+// ticker is run as a goroutine for each job to start executions on the job interval.
+func (s *Scheduler) ticker(j *job) {
+	defer s.tickers.Done()
+
+	s.due(j)
+
+	ticker := s.clock.NewTicker(j.interval)
+	defer ticker.Stop()
+	for {
+		// Use two select statements to prioritize stop channel over ticker.
+		select {
+		case <-s.stop:
+			return
+		default:
+		}
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C():
+			s.due(j)
+		}
+	}
+}
+
+// due handles a job that is due for execution according to its overlap policy.
+func (s *Scheduler) due(j *job) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.stopped {
+		return
+	}
+
+	if j.stats.Running > 0 {
+		switch j.overlap {
+		case OverlapSkip:
+			j.stats.Skipped++
+			return
+		case OverlapQueue:
+			j.stats.Queued++
+			return
+		}
+	}
+
+	j.stats.Running++
+	s.running.Add(1)
+	go s.execute(j, j.next())
+}
+
+// execute is run as a goroutine to run a job one or more times.
+// Multiple executions only occur for queued executions of OverlapQueue jobs.
+func (s *Scheduler) execute(j *job, cycle uint) {
+	defer s.running.Done()
+	for {
+		start := s.clock.Now()
+		err := j.cycleFn(cycle)
+		duration := s.clock.Since(start)
+
+		s.lock.Lock()
+		j.stats.Runs++
+		j.stats.LastRun = start
+		j.stats.LastDuration = duration
+		if err != nil {
+			j.stats.Errors++
+			j.stats.LastError = err
+		}
+		if j.stats.Queued > 0 && !s.stopped {
+			j.stats.Queued--
+			cycle = j.next()
+			s.lock.Unlock()
+			continue
+		}
+		j.stats.Queued = 0
+		j.stats.Running--
+		s.lock.Unlock()
+		return
+	}
+}
+
+// next returns the next cycle number for the job.
+// Must be called with the Scheduler lock held.
+func (j *job) next() uint {
+	cycle := j.cycles
+	j.cycles++
+	return cycle
+}
@@ -0,0 +1,249 @@
+package cycle
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/madkins23/go-utils/clock"
+)
+
+func ExampleScheduler() {
+	s := NewScheduler(nil)
+	if err := s.Add("hello", 10*time.Millisecond, func(cycles uint) error {
+		if cycles < 2 {
+			fmt.Println("Hello", cycles)
+		}
+		return nil
+	}, OverlapSkip); err != nil {
+		panic(err)
+	}
+	if err := s.Start(); err != nil {
+		panic(err)
+	}
+	time.Sleep(35 * time.Millisecond)
+	s.Stop()
+	stats, _ := s.Stats("hello")
+	fmt.Println(stats.Runs > 2, stats.Errors)
+	// Output: Hello 0
+	// Hello 1
+	// true 0
+}
+
+func TestScheduler_Add(t *testing.T) {
+	s := NewScheduler(nil)
+	require.NotNil(t, s)
+	assert.ErrorIs(t, s.Add("nil", time.Second, nil, OverlapSkip), errNoCycleFn)
+	assert.ErrorIs(t, s.Add("zero", 0, nilCycle, OverlapSkip), errBadInterval)
+	assert.NoError(t, s.Add("bravo", time.Second, nilCycle, OverlapSkip))
+	assert.NoError(t, s.Add("alpha", time.Second, nilCycle, OverlapSkip))
+	assert.ErrorIs(t, s.Add("alpha", time.Second, nilCycle, OverlapSkip), errJobExists)
+	assert.Equal(t, []string{"alpha", "bravo"}, s.Names())
+	require.NoError(t, s.Start())
+	assert.ErrorIs(t, s.Start(), errSchedulerState)
+	assert.ErrorIs(t, s.Add("charlie", time.Second, nilCycle, OverlapSkip), errSchedulerState)
+	s.Stop()
+	s.Stop()
+	s.Finished()
+}
+
+func TestScheduler_Jobs(t *testing.T) {
+	var lock sync.Mutex
+	counts := make(map[string]int)
+	counter := func(name string, err error) PeriodicFn {
+		return func(cycles uint) error {
+			lock.Lock()
+			defer lock.Unlock()
+			counts[name]++
+			return err
+		}
+	}
+	s := NewScheduler(nil)
+	require.NoError(t, s.Add("fast", 5*time.Millisecond, counter("fast", nil), OverlapSkip))
+	require.NoError(t, s.Add("slow", 20*time.Millisecond, counter("slow", nil), OverlapSkip))
+	require.NoError(t, s.Add("fail", 10*time.Millisecond, counter("fail", errors.New("failure")), OverlapSkip))
+	require.NoError(t, s.Start())
+	time.Sleep(50 * time.Millisecond)
+	s.Stop()
+
+	lock.Lock()
+	defer lock.Unlock()
+	assert.Greater(t, counts["fast"], counts["slow"])
+	assert.GreaterOrEqual(t, counts["slow"], 2)
+	stats, found := s.Stats("fail")
+	require.True(t, found)
+	assert.Equal(t, uint(counts["fail"]), stats.Runs)
+	assert.Equal(t, stats.Runs, stats.Errors)
+	assert.EqualError(t, stats.LastError, "failure")
+	assert.False(t, stats.LastRun.IsZero())
+	_, found = s.Stats("missing")
+	assert.False(t, found)
+}
+
+func TestScheduler_Overlap(t *testing.T) {
+	var lock sync.Mutex
+	var current, maximum int
+	sleeper := func(cycles uint) error {
+		lock.Lock()
+		current++
+		if current > maximum {
+			maximum = current
+		}
+		lock.Unlock()
+		time.Sleep(25 * time.Millisecond)
+		lock.Lock()
+		current--
+		lock.Unlock()
+		return nil
+	}
+
+	for _, policy := range []OverlapPolicy{OverlapSkip, OverlapQueue, OverlapConcurrent} {
+		current, maximum = 0, 0
+		s := NewScheduler(nil)
+		require.NoError(t, s.Add("sleeper", 5*time.Millisecond, sleeper, policy))
+		require.NoError(t, s.Start())
+		time.Sleep(60 * time.Millisecond)
+		s.Stop()
+
+		stats, found := s.Stats("sleeper")
+		require.True(t, found)
+		assert.Zero(t, stats.Running, "stop waits for in-flight jobs")
+		assert.Zero(t, current)
+		switch policy {
+		case OverlapSkip:
+			assert.Equal(t, 1, maximum)
+			assert.Greater(t, stats.Skipped, uint(0))
+			assert.Contains(t, []uint{2, 3}, stats.Runs)
+		case OverlapQueue:
+			assert.Equal(t, 1, maximum)
+			assert.Zero(t, stats.Skipped)
+			assert.Zero(t, stats.Queued, "queued jobs discarded on stop")
+			assert.Contains(t, []uint{3, 4}, stats.Runs)
+		case OverlapConcurrent:
+			assert.Greater(t, maximum, 1)
+			assert.Zero(t, stats.Skipped)
+			assert.Greater(t, stats.Runs, uint(3))
+		}
+	}
+}
+
+func TestScheduler_Interrupt(t *testing.T) {
+	var signal os.Signal
+	s := NewScheduler(func(sig os.Signal) {
+		signal = sig
+	})
+	require.NoError(t, s.Add("job", 5*time.Millisecond, nilCycle, OverlapSkip))
+	require.NoError(t, s.Start())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		s.signals <- syscall.SIGINT
+	}()
+	s.Finished()
+	assert.Equal(t, os.Interrupt, signal)
+}
+
+func TestScheduler_RequestStopFromJob(t *testing.T) {
+	s := NewScheduler(nil)
+	require.NoError(t, s.Add("fatal", time.Millisecond, func(cycle uint) error {
+		if cycle == 2 {
+			s.RequestStop()
+			return errors.New("fatal")
+		}
+		return nil
+	}, OverlapSkip))
+	require.NoError(t, s.Start())
+	finished := make(chan bool)
+	go func() {
+		s.Finished()
+		s.Stop()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("scheduler did not stop")
+	}
+	stats, _ := s.Stats("fatal")
+	assert.Equal(t, uint(3), stats.Runs)
+	assert.Equal(t, uint(1), stats.Errors)
+}
+
+func nilCycle(_ uint) error {
+	return nil
+}
+
+func TestScheduler_FakeClockQueue(t *testing.T) {
+	fake := clock.NewFake(time.Time{})
+	ran := make(chan uint)
+	release := make(chan bool)
+	s := NewScheduler(nil)
+	s.SetClock(fake)
+	require.NoError(t, s.Add("queue", time.Minute, func(cycles uint) error {
+		ran <- cycles
+		<-release
+		return nil
+	}, OverlapQueue))
+	require.NoError(t, s.Start())
+
+	assert.Equal(t, uint(0), <-ran)
+	fake.BlockUntil(1)
+	for queued := uint(1); queued <= 2; queued++ {
+		fake.Advance(time.Minute)
+		eventually(t, s, "queue", func(stats JobStats) bool {
+			return stats.Queued == queued
+		})
+	}
+	for i := uint(1); i <= 2; i++ {
+		release <- true
+		assert.Equal(t, i, <-ran)
+	}
+	release <- true
+	eventually(t, s, "queue", func(stats JobStats) bool {
+		return stats.Running == 0
+	})
+	s.Stop()
+	stats, _ := s.Stats("queue")
+	assert.Equal(t, uint(3), stats.Runs)
+	assert.Zero(t, stats.Queued)
+	assert.Zero(t, stats.Skipped)
+}
+
+func TestScheduler_FakeClockSkip(t *testing.T) {
+	fake := clock.NewFake(time.Time{})
+	s := NewScheduler(nil)
+	s.SetClock(fake)
+	require.NoError(t, s.Add("skip", time.Minute, func(cycles uint) error {
+		fake.Sleep(90 * time.Second)
+		return nil
+	}, OverlapSkip))
+	require.NoError(t, s.Start())
+
+	// Wait for the ticker and the first execution sleeper.
+	fake.BlockUntil(2)
+	fake.Advance(time.Minute)
+	eventually(t, s, "skip", func(stats JobStats) bool {
+		return stats.Skipped == 1
+	})
+	fake.Advance(30 * time.Second)
+	eventually(t, s, "skip", func(stats JobStats) bool {
+		return stats.Runs == 1
+	})
+	s.Stop()
+	stats, _ := s.Stats("skip")
+	assert.Equal(t, 90*time.Second, stats.LastDuration)
+	assert.Zero(t, stats.Running)
+}
+
+func eventually(t *testing.T, s *Scheduler, name string, condition func(stats JobStats) bool) {
+	assert.Eventually(t, func() bool {
+		stats, found := s.Stats(name)
+		return found && condition(stats)
+	}, time.Second, time.Millisecond)
+}
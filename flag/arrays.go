@@ -0,0 +1,75 @@
+package flag
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IntArray defines a flag that can be invoked multiple times with integer values accumulated in an array.
+// Comma-separated values may be combined in a single flag argument as with StringArray.
+type IntArray []int
+
+// String representation of the array of flag values.
+func (i *IntArray) String() string {
+	return joinValues(*i)
+}
+
+// Set a value(s) into the array.
+func (i *IntArray) Set(value string) error {
+	values, err := parseList(value, strconv.Atoi, nil)
+	if err != nil {
+		return err
+	}
+	*i = append(*i, values...)
+	return nil
+}
+
+func (i *IntArray) reset() {
+	*i = nil
+}
+
+func (i *IntArray) itemSeparator() rune {
+	return ','
+}
+
+//////////////////////////////////////////////////////////////////////////
+
+// DurationArray defines a flag that can be invoked multiple times with
+// time.Duration values (e.g. "1h30m") accumulated in an array.
+// Comma-separated values may be combined in a single flag argument as with StringArray.
+type DurationArray []time.Duration
+
+// String representation of the array of flag values.
+func (d *DurationArray) String() string {
+	return joinValues(*d)
+}
+
+// Set a value(s) into the array.
+func (d *DurationArray) Set(value string) error {
+	values, err := parseList(value, time.ParseDuration, nil)
+	if err != nil {
+		return err
+	}
+	*d = append(*d, values...)
+	return nil
+}
+
+func (d *DurationArray) reset() {
+	*d = nil
+}
+
+func (d *DurationArray) itemSeparator() rune {
+	return ','
+}
+
+//////////////////////////////////////////////////////////////////////////
+
+func joinValues[T any](values []T) string {
+	items := make([]string, len(values))
+	for i, value := range values {
+		items[i] = fmt.Sprint(value)
+	}
+	return "[" + strings.Join(items, ",") + "]"
+}
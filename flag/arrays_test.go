@@ -0,0 +1,51 @@
+package flag
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFlagSet returns a flag set that returns errors and discards usage output.
+func newFlagSet() *flag.FlagSet {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	return flags
+}
+
+func TestIntArray(t *testing.T) {
+	var ints IntArray
+	flags := newFlagSet()
+	flags.Var(&ints, "int", "Some description for this param.")
+	require.NoError(t, flags.Parse([]string{"-int", "1", "-int", "2, 3,-4"}))
+	assert.Equal(t, IntArray{1, 2, 3, -4}, ints)
+	assert.Equal(t, "[1,2,3,-4]", ints.String())
+
+	err := flags.Parse([]string{"-int", "5,six"})
+	require.Error(t, err)
+	assert.Equal(t, `invalid value "5,six" for flag -int: parse "six": invalid syntax`, err.Error())
+	assert.Len(t, ints, 4, "no partial values")
+}
+
+func TestDurationArray(t *testing.T) {
+	var durations DurationArray
+	flags := newFlagSet()
+	flags.Var(&durations, "duration", "Some description for this param.")
+	require.NoError(t, flags.Parse([]string{"-duration", "1s,1h30m"}))
+	assert.Equal(t, DurationArray{time.Second, 90 * time.Minute}, durations)
+	assert.Error(t, flags.Parse([]string{"-duration", "soon"}))
+}
+
+func Example_intArrayFlags() {
+	var ints IntArray
+	flags := flag.NewFlagSet("example", flag.ContinueOnError)
+	flags.Var(&ints, "port", "Some description for this param.")
+	_ = flags.Parse([]string{"-port", "80", "-port", "443,8080"})
+	fmt.Println(ints)
+	// Output: [80 443 8080]
+}
@@ -0,0 +1,247 @@
+package flag
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	errBindTarget = errors.New("bind target must be a pointer to a struct")
+	errBindType   = errors.New("unsupported field type")
+	errBindExists = errors.New("flag already defined")
+)
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+	valueType    = reflect.TypeOf((*flag.Value)(nil)).Elem()
+)
+
+// Bind registers flags in the specified flag.FlagSet for fields in the configuration struct.
+// The cfg argument must be a pointer to a struct.
+// Fields are configured using struct tags:
+//
+//	flag:"name"     name of the flag, a field without this tag is not bound,
+//	                "-" explicitly skips the field
+//	usage:"text"    usage text for the flag
+//	default:"value" default value for the flag, otherwise the current field value is the default
+//	env:"NAME"      environment variable that overrides the default value if it is set
+//
+// Supported field types are bool, string, int, int64, uint, uint64, float64, time.Duration,
+// any type with one of those as its underlying type,
+// and any type whose pointer implements flag.Value (e.g. StringArray or StringMap).
+//
+// Nested struct fields are walked recursively.
+// Flags for the fields of a nested struct are prefixed with the name from its flag tag
+// (or its field name with a lower case first letter) followed by a period.
+// The fields of embedded structs are bound without a prefix.
+//
+// Values from environment variables and default tags are applied immediately,
+// so the flag set should be parsed after calling Bind so that the command line takes precedence.
+// Settings files loaded after calling Bind override environment variables,
+// use Config.Bind for environment variables that override settings files.
+// Flags that accumulate values (e.g. StringArray) replace their default or environment value
+// when they are set instead of adding to it.
+// All errors are collected and returned together.
+func Bind(flagSet *flag.FlagSet, cfg interface{}) error {
+	return bind(flagSet, cfg, nil)
+}
+
+// bind registers flags for the fields of the configuration struct.
+// If the env function is not nil it is called with the flag name and environment variable name
+// of each field with an env tag instead of applying the environment variable.
+func bind(flagSet *flag.FlagSet, cfg interface{}, env func(name, envName string)) error {
+	value := reflect.ValueOf(cfg)
+	if value.Kind() != reflect.Pointer || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return errBindTarget
+	}
+	errs := make([]error, 0)
+	bindStruct(flagSet, value.Elem(), "", env, &errs)
+	if len(errs) > 0 {
+		return fmt.Errorf("bind flags: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// bindStruct binds the fields of a struct value, accumulating errors.
+func bindStruct(flagSet *flag.FlagSet, structValue reflect.Value, prefix string, env func(name, envName string), errs *[]error) {
+	structType := structValue.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		fieldValue := structValue.Field(i)
+		name, tagged := field.Tag.Lookup("flag")
+		if name == "-" || !field.IsExported() && !field.Anonymous {
+			continue
+		}
+		if isNested(field.Type) {
+			nested := prefix
+			if !field.Anonymous || tagged {
+				if !tagged || name == "" {
+					name = lowerFirst(field.Name)
+				}
+				nested = prefix + name + "."
+			}
+			bindStruct(flagSet, fieldValue, nested, env, errs)
+			continue
+		}
+		if !tagged || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = lowerFirst(field.Name)
+		}
+		name = prefix + name
+		if err := bindField(flagSet, fieldValue, name, field.Tag, env); err != nil {
+			*errs = append(*errs, fmt.Errorf("field %s (flag %s): %w", field.Name, name, err))
+		}
+	}
+}
+
+// isNested returns true if the type is a struct to be walked recursively.
+func isNested(fieldType reflect.Type) bool {
+	return fieldType.Kind() == reflect.Struct &&
+		fieldType != timeType &&
+		!reflect.PointerTo(fieldType).Implements(valueType)
+}
+
+// bindField registers a single flag for a field and applies default and environment values.
+// If the env function is not nil it is called instead of applying the environment value.
+func bindField(flagSet *flag.FlagSet, fieldValue reflect.Value, name string, tag reflect.StructTag, env func(name, envName string)) error {
+	if flagSet.Lookup(name) != nil {
+		return errBindExists
+	}
+	usage := tag.Get("usage")
+	envName := tag.Get("env")
+	if envName != "" {
+		usage += " [$" + envName + "]"
+	}
+	if err := bindVar(flagSet, fieldValue, name, usage); err != nil {
+		return err
+	}
+	flg := flagSet.Lookup(name)
+	if def, ok := tag.Lookup("default"); ok {
+		if err := flg.Value.Set(def); err != nil {
+			return fmt.Errorf("set default '%s': %w", def, err)
+		}
+		flg.DefValue = def
+		markDefault(flg)
+	}
+	if envName == "" {
+		return nil
+	} else if env != nil {
+		env(name, envName)
+	} else if value, ok := os.LookupEnv(envName); ok {
+		if err := flg.Value.Set(value); err != nil {
+			return fmt.Errorf("set from environment variable %s to '%s': %w", envName, value, err)
+		}
+		flg.DefValue = value
+		markDefault(flg)
+	}
+	return nil
+}
+
+// bindVar registers the appropriate type of flag for a field.
+// The current field value is used as the default value.
+// Flag values that accumulate values are wrapped so that the default value is replaced when the flag is set.
+func bindVar(flagSet *flag.FlagSet, fieldValue reflect.Value, name, usage string) error {
+	ptr := fieldValue.Addr()
+	if value, ok := ptr.Interface().(flag.Value); ok {
+		if _, ok := value.(resetter); ok {
+			value = &defaultValue{Value: value, isDefault: true}
+		}
+		flagSet.Var(value, name, usage)
+		return nil
+	}
+	if fieldValue.Type() == durationType {
+		p := ptr.Interface().(*time.Duration)
+		flagSet.DurationVar(p, name, *p, usage)
+		return nil
+	}
+	switch fieldValue.Kind() {
+	case reflect.Bool:
+		p := convertPtr[bool](ptr)
+		flagSet.BoolVar(p, name, *p, usage)
+	case reflect.String:
+		p := convertPtr[string](ptr)
+		flagSet.StringVar(p, name, *p, usage)
+	case reflect.Int:
+		p := convertPtr[int](ptr)
+		flagSet.IntVar(p, name, *p, usage)
+	case reflect.Int64:
+		p := convertPtr[int64](ptr)
+		flagSet.Int64Var(p, name, *p, usage)
+	case reflect.Uint:
+		p := convertPtr[uint](ptr)
+		flagSet.UintVar(p, name, *p, usage)
+	case reflect.Uint64:
+		p := convertPtr[uint64](ptr)
+		flagSet.Uint64Var(p, name, *p, usage)
+	case reflect.Float64:
+		p := convertPtr[float64](ptr)
+		flagSet.Float64Var(p, name, *p, usage)
+	default:
+		return fmt.Errorf("%w %s", errBindType, fieldValue.Type())
+	}
+	return nil
+}
+
+// markDefault marks the current value of a bound flag as a default value to be replaced when the flag is set.
+func markDefault(flg *flag.Flag) {
+	if value, ok := flg.Value.(*defaultValue); ok {
+		value.isDefault = true
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////
+
+// defaultValue wraps a bound flag value that accumulates values (e.g. StringArray)
+// so that its default value is replaced by, rather than combined with,
+// the values from the first source that sets the flag.
+type defaultValue struct {
+	flag.Value
+	isDefault bool
+}
+
+// Set removes the default value before setting the first value.
+func (dv *defaultValue) Set(value string) error {
+	if dv.isDefault {
+		dv.reset()
+		dv.isDefault = false
+	}
+	return dv.Value.Set(value)
+}
+
+// Get returns the value of the wrapped flag value.
+func (dv *defaultValue) Get() interface{} {
+	if getter, ok := dv.Value.(flag.Getter); ok {
+		return getter.Get()
+	}
+	return dv.Value
+}
+
+func (dv *defaultValue) reset() {
+	dv.Value.(resetter).reset()
+}
+
+func (dv *defaultValue) settingValues() []string {
+	return settingValues(&flag.Flag{Value: dv.Value})
+}
+
+//////////////////////////////////////////////////////////////////////////
+
+// convertPtr converts a pointer to a field into a pointer to its underlying basic type.
+func convertPtr[T any](ptr reflect.Value) *T {
+	return ptr.Convert(reflect.TypeOf((*T)(nil))).Interface().(*T)
+}
+
+// lowerFirst returns the string with its first letter in lower case.
+func lowerFirst(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[size:]
+}
@@ -0,0 +1,158 @@
+package flag
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type bindMode string
+
+type bindServer struct {
+	Host    string        `flag:"host" usage:"Server host name" default:"localhost"`
+	Port    int           `flag:"port" usage:"Server port" env:"TEST_BIND_PORT"`
+	Timeout time.Duration `flag:"timeout" usage:"Request timeout" default:"5s"`
+}
+
+type bindCommon struct {
+	Verbose bool `flag:"verbose" usage:"Verbose output"`
+}
+
+type bindConfig struct {
+	bindCommon
+	Name     string      `flag:"name" usage:"Application name"`
+	Mode     bindMode    `flag:"mode" default:"fast"`
+	Count    uint        `flag:"count"`
+	Size     int64       `flag:"size"`
+	Max      uint64      `flag:"max"`
+	Ratio    float64     `flag:"ratio" default:"0.5"`
+	Tags     StringArray `flag:"tag" usage:"Tags (may be repeated)"`
+	Labels   StringMap   `flag:"label"`
+	Level    *Enum       `flag:"-"`
+	Server   bindServer
+	Backup   bindServer `flag:"bak"`
+	Ignored  string
+	internal string `flag:"internal"`
+}
+
+func TestBind(t *testing.T) {
+	require.NoError(t, os.Setenv("TEST_BIND_PORT", "8080"))
+	defer func() {
+		_ = os.Unsetenv("TEST_BIND_PORT")
+	}()
+	cfg := bindConfig{Name: "original"}
+	flags := newFlagSet()
+	require.NoError(t, Bind(flags, &cfg))
+	for _, name := range []string{"verbose", "name", "mode", "count", "size", "max", "ratio", "tag", "label",
+		"server.host", "server.port", "server.timeout", "bak.host", "bak.port", "bak.timeout"} {
+		assert.NotNil(t, flags.Lookup(name), name)
+	}
+	for _, name := range []string{"Level", "level", "Ignored", "ignored", "internal", "bindCommon.verbose"} {
+		assert.Nil(t, flags.Lookup(name), name)
+	}
+
+	// Defaults and environment.
+	assert.Equal(t, "original", cfg.Name)
+	assert.Equal(t, bindMode("fast"), cfg.Mode)
+	assert.Equal(t, 0.5, cfg.Ratio)
+	assert.Equal(t, "localhost", cfg.Server.Host)
+	assert.Equal(t, 8080, cfg.Server.Port)
+	assert.Equal(t, "8080", flags.Lookup("server.port").DefValue)
+	assert.Equal(t, 5*time.Second, cfg.Backup.Timeout)
+	assert.Equal(t, "Server port [$TEST_BIND_PORT]", flags.Lookup("bak.port").Usage)
+
+	require.NoError(t, flags.Parse([]string{
+		"-verbose", "-name", "test", "-mode", "slow", "-count", "3", "-size", "-4", "-max", "5", "-ratio", "1.5",
+		"-tag", "a,b", "-label", "x:y", "-server.host", "example.com", "-bak.port", "9090", "-bak.timeout", "1m"}))
+	assert.True(t, cfg.Verbose)
+	assert.Equal(t, "test", cfg.Name)
+	assert.Equal(t, bindMode("slow"), cfg.Mode)
+	assert.Equal(t, uint(3), cfg.Count)
+	assert.Equal(t, int64(-4), cfg.Size)
+	assert.Equal(t, uint64(5), cfg.Max)
+	assert.Equal(t, 1.5, cfg.Ratio)
+	assert.Equal(t, StringArray{"a", "b"}, cfg.Tags)
+	assert.Equal(t, StringMap{"x": "y"}, cfg.Labels)
+	assert.Equal(t, "example.com", cfg.Server.Host)
+	assert.Equal(t, 9090, cfg.Backup.Port)
+	assert.Equal(t, time.Minute, cfg.Backup.Timeout)
+}
+
+type bindAccumulate struct {
+	Tags   StringArray `flag:"tags" default:"a,b" env:"TEST_BIND_TAGS"`
+	Labels StringMap   `flag:"labels" default:"i:1" env:"TEST_BIND_LABELS"`
+}
+
+func TestBind_accumulate(t *testing.T) {
+	bindFlags := func(args ...string) (*bindAccumulate, *flag.FlagSet) {
+		cfg := &bindAccumulate{}
+		flags := newFlagSet()
+		require.NoError(t, Bind(flags, cfg))
+		require.NoError(t, flags.Parse(args))
+		return cfg, flags
+	}
+	cfg, flags := bindFlags()
+	assert.Equal(t, StringArray{"a", "b"}, cfg.Tags)
+	assert.Equal(t, StringMap{"i": "1"}, cfg.Labels)
+	assert.Equal(t, "[a,b]", flags.Lookup("tags").Value.String())
+	assert.Equal(t, []string{"a", "b"}, settingValues(flags.Lookup("tags")))
+	assert.Equal(t, []string{"i:1"}, settingValues(flags.Lookup("labels")))
+	cfg, _ = bindFlags("-tags", "c", "-tags", "d", "-labels", "k:3")
+	assert.Equal(t, StringArray{"c", "d"}, cfg.Tags, "command line replaces default")
+	assert.Equal(t, StringMap{"k": "3"}, cfg.Labels)
+
+	t.Setenv("TEST_BIND_TAGS", "c")
+	t.Setenv("TEST_BIND_LABELS", "k:3")
+	cfg, flags = bindFlags()
+	assert.Equal(t, StringArray{"c"}, cfg.Tags, "environment replaces default")
+	assert.Equal(t, StringMap{"k": "3"}, cfg.Labels)
+	assert.Equal(t, "c", flags.Lookup("tags").DefValue)
+	cfg, _ = bindFlags("-tags", "d", "-labels", "l:4")
+	assert.Equal(t, StringArray{"d"}, cfg.Tags, "command line replaces environment")
+	assert.Equal(t, StringMap{"l": "4"}, cfg.Labels)
+}
+
+func TestBind_errors(t *testing.T) {
+	assert.ErrorIs(t, Bind(newFlagSet(), nil), errBindTarget)
+	assert.ErrorIs(t, Bind(newFlagSet(), bindServer{}), errBindTarget)
+	assert.ErrorIs(t, Bind(newFlagSet(), (*bindServer)(nil)), errBindTarget)
+	var str string
+	assert.ErrorIs(t, Bind(newFlagSet(), &str), errBindTarget)
+
+	err := Bind(newFlagSet(), &struct {
+		Bad     []int     `flag:"bad"`
+		Time    time.Time `flag:"time"`
+		Default int       `flag:"default" default:"many"`
+	}{})
+	assert.ErrorIs(t, err, errBindType)
+	assert.ErrorContains(t, err, "field Bad (flag bad): unsupported field type []int")
+	assert.ErrorContains(t, err, "field Time (flag time): unsupported field type time.Time")
+	assert.ErrorContains(t, err, "field Default (flag default): set default 'many'")
+
+	flags := newFlagSet()
+	flags.String("host", "", "")
+	assert.ErrorIs(t, Bind(flags, &bindServer{}), errBindExists)
+}
+
+func ExampleBind() {
+	var cfg struct {
+		Host string `flag:"host" usage:"Server host name" default:"localhost"`
+		Port int    `flag:"port" usage:"Server port" default:"80"`
+		Log  struct {
+			File  string `flag:"file" usage:"Log file path"`
+			Level string `flag:"level" usage:"Log level" default:"info"`
+		}
+	}
+	flags := flag.NewFlagSet("example", flag.ContinueOnError)
+	if err := Bind(flags, &cfg); err != nil {
+		fmt.Println(err)
+	}
+	_ = flags.Parse([]string{"-port", "8080", "-log.file", "/tmp/app.log"})
+	fmt.Printf("%+v\n", cfg)
+	// Output: {Host:localhost Port:8080 Log:{File:/tmp/app.log Level:info}}
+}
@@ -0,0 +1,176 @@
+package flag
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	errMissingEquals  = errors.New("missing '='")
+	errEmptyKey       = errors.New("empty key")
+	errInvalidKey     = errors.New("invalid key")
+	errBadSection     = errors.New("invalid section header")
+	errUnterminated   = errors.New("unterminated quoted string")
+	errTrailingText   = errors.New("unexpected text after quoted string")
+	errBadEscape      = errors.New("invalid escape in quoted string")
+	errNoContinuation = errors.New("line continuation at end of file")
+)
+
+// parseConfig parses a CFG or INI settings file.
+//
+//	# Lines starting with '#' or ';' are comments.
+//	key = value
+//	quoted = "  leading and trailing spaces,\ttabs and \"escapes\"\n"  # comment
+//	literal = 'single quotes without escapes'
+//	"key=with=equals" = value
+//	long = first part \
+//	       second part
+//	[section]
+//	key = value for the flag 'section.key'
+//
+// Unquoted values are taken verbatim from the '=' to the end of the line
+// with surrounding white space removed.
+// Double quoted strings support the same escapes as Go string literals.
+// A quoted key or value may be followed by a comment.
+// A backslash at the end of a line other than a comment joins the next line
+// (without its leading white space).
+// Keys within a '[section]' are prefixed with the section name and a period.
+// An empty section name ('[]') removes the prefix.
+// Repeated keys set multiple values (e.g. for StringArray flags).
+// Errors include the line number.
+func parseConfig(text string) (map[string][]string, error) {
+	settings := make(map[string][]string)
+	prefix := ""
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		number := i + 1
+		line := strings.TrimSpace(lines[i])
+		if line == "" || isComment(line) {
+			// Comments are not continued even if they end with a backslash.
+			continue
+		}
+		for continued(line) {
+			if i++; i >= len(lines) {
+				return nil, fmt.Errorf("line %d: %w", number, errNoContinuation)
+			}
+			line = line[:len(line)-1] + strings.TrimSpace(lines[i])
+		}
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: %w '%s'", number, errBadSection, line)
+			}
+			prefix = strings.TrimSpace(line[1 : len(line)-1])
+			if prefix != "" {
+				prefix += "."
+			}
+		} else if key, value, err := parseSetting(line); err != nil {
+			return nil, fmt.Errorf("line %d: %w", number, err)
+		} else {
+			key = prefix + key
+			settings[key] = append(settings[key], value)
+		}
+	}
+	return settings, nil
+}
+
+// parseSetting parses a 'key = value' line.
+func parseSetting(line string) (string, string, error) {
+	var key, rest string
+	if isQuote(line[0]) {
+		var err error
+		if key, rest, err = parseQuoted(line); err != nil {
+			return "", "", fmt.Errorf("key: %w", err)
+		}
+		rest = strings.TrimLeft(rest, " \t")
+		if !strings.HasPrefix(rest, "=") {
+			return "", "", fmt.Errorf("%w after key in '%s'", errMissingEquals, line)
+		}
+	} else {
+		index := strings.IndexByte(line, '=')
+		if index < 0 {
+			return "", "", fmt.Errorf("%w in '%s'", errMissingEquals, line)
+		}
+		key, rest = strings.TrimSpace(line[:index]), line[index:]
+		if strings.ContainsAny(key, " \t") {
+			return "", "", fmt.Errorf("%w '%s'", errInvalidKey, key)
+		}
+	}
+	if key == "" {
+		return "", "", fmt.Errorf("%w in '%s'", errEmptyKey, line)
+	}
+
+	rest = strings.TrimSpace(rest[1:])
+	if rest == "" || !isQuote(rest[0]) {
+		return key, rest, nil
+	}
+	value, rest, err := parseQuoted(rest)
+	if err != nil {
+		return "", "", fmt.Errorf("value for '%s': %w", key, err)
+	}
+	if rest = strings.TrimSpace(rest); rest != "" && !isComment(rest) {
+		return "", "", fmt.Errorf("value for '%s': %w '%s'", key, errTrailingText, rest)
+	}
+	return key, value, nil
+}
+
+// parseQuoted parses the quoted string at the start of the text
+// and returns its value and the remaining text.
+func parseQuoted(text string) (string, string, error) {
+	quote := text[0]
+	for i := 1; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			if quote == '"' {
+				// Skip the escaped character.
+				i++
+			}
+		case quote:
+			if quote == '\'' {
+				return text[1:i], text[i+1:], nil
+			}
+			value, err := strconv.Unquote(text[:i+1])
+			if err != nil {
+				return "", "", fmt.Errorf("%w: %s", errBadEscape, text[:i+1])
+			}
+			return value, text[i+1:], nil
+		}
+	}
+	return "", "", fmt.Errorf("%w: %s", errUnterminated, text)
+}
+
+// continued returns true if the line ends with an odd number of backslashes.
+func continued(line string) bool {
+	count := len(line) - len(strings.TrimRight(line, "\\"))
+	return count%2 == 1
+}
+
+func isComment(text string) bool {
+	return text[0] == '#' || text[0] == ';'
+}
+
+func isQuote(char byte) bool {
+	return char == '"' || char == '\''
+}
+
+//////////////////////////////////////////////////////////////////////////
+
+// quoteConfig returns the text quoted for a CFG or INI settings file if necessary
+// so that it is parsed back unchanged by parseConfig.
+func quoteConfig(text string, key bool) string {
+	needed := text == "" && key ||
+		text != strings.TrimSpace(text) ||
+		strings.ContainsAny(text, "\n\r") ||
+		continued(text)
+	if text != "" {
+		needed = needed || isQuote(text[0])
+		if key {
+			needed = needed || strings.ContainsAny(text, "= \t") || isComment(text) || text[0] == '['
+		}
+	}
+	if needed {
+		return strconv.Quote(text)
+	}
+	return text
+}
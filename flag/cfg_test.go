@@ -0,0 +1,110 @@
+package flag
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfig(t *testing.T) {
+	settings, err := parseConfig(`
+# Comment
+  ; Another comment
+plain = Just some text  
+empty =
+equals = a=b
+-dash = flag style
+"key=with=equals" = value
+'single key' = 'C:\path\'   # comment
+quoted = "  spaces\tand \"escapes\"\n" ; comment
+unicode = "\u00e9t\u00e9"
+long = first part \
+       second part
+multi = "line one\n\
+line two"
+tag = alpha
+tag = bravo
+[server]
+host = example.com
+  [ sub.section ]
+port = 8080
+[]
+top = level
+`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"plain":            {"Just some text"},
+		"empty":            {""},
+		"equals":           {"a=b"},
+		"-dash":            {"flag style"},
+		"key=with=equals":  {"value"},
+		"single key":       {`C:\path\`},
+		"quoted":           {"  spaces\tand \"escapes\"\n"},
+		"unicode":          {"été"},
+		"long":             {"first part second part"},
+		"multi":            {"line one\nline two"},
+		"tag":              {"alpha", "bravo"},
+		"server.host":      {"example.com"},
+		"sub.section.port": {"8080"},
+		"top":              {"level"},
+	}, settings)
+}
+
+func TestParseConfig_commentBackslash(t *testing.T) {
+	settings, err := parseConfig(`
+# old = C:\dir\
+text = kept
+; also \
+`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"text": {"kept"}}, settings)
+}
+
+func TestParseConfig_crlf(t *testing.T) {
+	settings, err := parseConfig("a = 1\r\nb = \\\r\n  2\r\n")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"a": {"1"}, "b": {"2"}}, settings)
+}
+
+func TestParseConfig_errors(t *testing.T) {
+	for text, expected := range map[string]string{
+		"a = 1\nno equals":          "line 2: missing '=' in 'no equals'",
+		"= value":                   "line 1: empty key in '= value'",
+		"two words = value":         "line 1: invalid key 'two words'",
+		"\n\n[section":              "line 3: invalid section header '[section'",
+		`a = "unterminated`:         `line 1: value for 'a': unterminated quoted string: "unterminated`,
+		`"key = value`:              `line 1: key: unterminated quoted string: "key = value`,
+		`"key" value`:               `line 1: missing '=' after key in '"key" value'`,
+		`a = "bad \q escape"`:       `line 1: value for 'a': invalid escape in quoted string: "bad \q escape"`,
+		`a = "text" more`:           "line 1: value for 'a': unexpected text after quoted string 'more'",
+		"a = 1\nb = 2 \\":           "line 2: line continuation at end of file",
+		"x = 1\n\ny = \\\n2\nz = ,": "",
+	} {
+		_, err := parseConfig(text)
+		if expected == "" {
+			assert.NoError(t, err, text)
+		} else {
+			assert.EqualError(t, err, expected, text)
+		}
+	}
+}
+
+func TestQuoteConfig(t *testing.T) {
+	for _, text := range []string{
+		"", "plain", " leading", "trailing ", "line\nbreak", `back\`, `"quoted"`, "'single'", "a=b", "#hash", "[section]",
+	} {
+		settings, err := parseConfig("key = " + quoteConfig(text, false))
+		require.NoError(t, err, text)
+		assert.Equal(t, []string{text}, settings["key"], text)
+		if text != "" {
+			settings, err = parseConfig(quoteConfig(text, true) + " = value")
+			require.NoError(t, err, text)
+			assert.Equal(t, []string{"value"}, settings[text], text)
+		}
+	}
+	assert.Equal(t, "plain", quoteConfig("plain", true))
+	assert.Equal(t, "a=b", quoteConfig("a=b", false))
+	assert.Equal(t, `"a=b"`, quoteConfig("a=b", true))
+	assert.Equal(t, `" x "`, quoteConfig(" x ", false))
+}
@@ -0,0 +1,296 @@
+package flag
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/madkins23/go-utils/msg"
+)
+
+// RunFn is a function that executes a Command with its remaining arguments.
+type RunFn func(cmd *Command, args []string) error
+
+// Command is a sub-command registered with a Commander.
+type Command struct {
+	// Name of the command on the command line.
+	Name string
+
+	// Aliases are alternate names for the command.
+	Aliases []string
+
+	// Summary is a one-line description of the command.
+	Summary string
+
+	// Usage describes the arguments of the command (e.g. "[file...]").
+	Usage string
+
+	// Flags for the command, created by Commander.Add if nil.
+	Flags *flag.FlagSet
+
+	// Run executes the command.
+	Run RunFn
+}
+
+// Commander dispatches git-style sub-commands:
+//
+//	program [global flags] <command> [command flags] [arguments]
+//
+// Settings files may be specified as '@<path>' among the global flags or the command flags
+// and are loaded into the appropriate flag set using LoadSettingsFromArgs.
+// Commands may be specified by name, alias, or any unique prefix of a name or alias.
+// A 'help' command is provided to show usage for the program or a command.
+// Construct Commander objects using NewCommander().
+type Commander struct {
+	// Name of the program.
+	Name string
+
+	// Global flags shared by all commands.
+	Global *flag.FlagSet
+
+	// Output for usage and error messages, defaults to os.Stderr.
+	Output io.Writer
+
+	// SettingsOptions configure the loading of settings files.
+	SettingsOptions []SettingsOption
+
+	commands []*Command
+	byName   map[string]*Command
+}
+
+var (
+	errNoCommand      = errors.New("no command specified")
+	errUnknownCommand = errors.New("unknown command")
+	errAmbiguous      = errors.New("ambiguous command")
+	errCommandExists  = errors.New("command name already used")
+	errCommandInvalid = errors.New("command requires a name and a run function")
+)
+
+// NewCommander returns a new Commander for the named program with an empty set of global flags.
+func NewCommander(name string) *Commander {
+	c := &Commander{
+		Name:   name,
+		Global: flag.NewFlagSet(name, flag.ContinueOnError),
+		byName: make(map[string]*Command),
+	}
+	_ = c.Add(&Command{
+		Name:    "help",
+		Summary: "Show help for the program or a command",
+		Usage:   "[command]",
+		Run:     c.help,
+	})
+	return c
+}
+
+// Add a Command to the Commander.
+// Returns an error if the command has no name or run function
+// or if its name or any of its aliases are already in use.
+func (c *Commander) Add(cmd *Command) error {
+	if cmd.Name == "" || cmd.Run == nil {
+		return errCommandInvalid
+	}
+	for _, name := range append([]string{cmd.Name}, cmd.Aliases...) {
+		if _, found := c.byName[name]; found {
+			return fmt.Errorf("%w: %s", errCommandExists, name)
+		}
+	}
+	if cmd.Flags == nil {
+		cmd.Flags = flag.NewFlagSet(c.Name+" "+cmd.Name, flag.ContinueOnError)
+	}
+	c.commands = append(c.commands, cmd)
+	for _, name := range append([]string{cmd.Name}, cmd.Aliases...) {
+		c.byName[name] = cmd
+	}
+	return nil
+}
+
+// Lookup returns the Command with the specified name or alias.
+// If there is no exact match any unique prefix of a name or alias is accepted.
+func (c *Commander) Lookup(name string) (*Command, error) {
+	if cmd, found := c.byName[name]; found {
+		return cmd, nil
+	}
+	matches := make(map[*Command]bool)
+	matchNames := make([]string, 0)
+	for key, cmd := range c.byName {
+		if strings.HasPrefix(key, name) {
+			matches[cmd] = true
+			matchNames = append(matchNames, key)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("%w '%s'", errUnknownCommand, name)
+	case 1:
+		for cmd := range matches {
+			return cmd, nil
+		}
+	}
+	sort.Strings(matchNames)
+	return nil, fmt.Errorf("%w '%s' matches %s", errAmbiguous, name, strings.Join(matchNames, ", "))
+}
+
+// Run parses the arguments, which should not include the program name (e.g. os.Args[1:]),
+// and executes the specified command.
+// Usage errors (e.g. unknown commands or flags) have the msg.CodeInvalid code.
+// Returns flag.ErrHelp if help was requested via a -h or -help flag.
+func (c *Commander) Run(args []string) error {
+	out := c.output()
+	c.Global.SetOutput(out)
+	c.Global.Usage = c.usage
+
+	split := splitCommand(c.Global, args, newSettingsLoader(nil, c.SettingsOptions).prefix)
+	remaining, err := LoadSettingsFromArgs(c.Global, args[:split], c.SettingsOptions...)
+	if err != nil {
+		return msg.WithCode(err, msg.CodeInvalid)
+	} else if err = c.Global.Parse(remaining); err != nil {
+		return usageError(err)
+	} else if split >= len(args) {
+		c.usage()
+		return msg.WithCode(errNoCommand, msg.CodeInvalid)
+	}
+
+	cmd, err := c.Lookup(args[split])
+	if err != nil {
+		c.usage()
+		return msg.WithCode(err, msg.CodeInvalid)
+	}
+	cmd.Flags.SetOutput(out)
+	cmd.Flags.Usage = func() {
+		c.commandUsage(cmd)
+	}
+	if remaining, err = LoadSettingsFromArgs(cmd.Flags, args[split+1:], c.SettingsOptions...); err != nil {
+		return msg.WithCode(err, msg.CodeInvalid)
+	} else if err = cmd.Flags.Parse(remaining); err != nil {
+		return usageError(err)
+	}
+	return cmd.Run(cmd, cmd.Flags.Args())
+}
+
+// Execute runs the Commander with the specified arguments and returns a process exit code.
+// Errors other than help requests are written to the output.
+// The exit code is derived from the error using msg.CodeOf() and msg.ExitCode().
+//
+//	os.Exit(commander.Execute(os.Args[1:]))
+func (c *Commander) Execute(args []string) int {
+	err := c.Run(args)
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	_, _ = fmt.Fprintf(c.output(), "%s: %s\n", c.Name, err)
+	return msg.ExitCode(msg.CodeOf(err))
+}
+
+// help implements the built-in help command.
+func (c *Commander) help(_ *Command, args []string) error {
+	if len(args) < 1 {
+		c.usage()
+		return nil
+	}
+	cmd, err := c.Lookup(args[0])
+	if err != nil {
+		return msg.WithCode(err, msg.CodeInvalid)
+	}
+	c.commandUsage(cmd)
+	return nil
+}
+
+// usage writes usage for the program to the output.
+func (c *Commander) usage() {
+	out := c.output()
+	_, _ = fmt.Fprintf(out, "Usage: %s [global flags] <command> [command flags] [arguments]\n\nCommands:\n", c.Name)
+	commands := append([]*Command{}, c.commands...)
+	sort.Slice(commands, func(i, j int) bool {
+		return commands[i].Name < commands[j].Name
+	})
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	for _, cmd := range commands {
+		_, _ = fmt.Fprintf(tw, "  %s\t%s\n", strings.Join(append([]string{cmd.Name}, cmd.Aliases...), ", "), cmd.Summary)
+	}
+	_ = tw.Flush()
+	if hasFlags(c.Global) {
+		_, _ = fmt.Fprintln(out, "\nGlobal flags:")
+		c.Global.SetOutput(out)
+		c.Global.PrintDefaults()
+	}
+}
+
+// commandUsage writes usage for the specified command to the output.
+func (c *Commander) commandUsage(cmd *Command) {
+	out := c.output()
+	_, _ = fmt.Fprintf(out, "Usage: %s [global flags] %s [flags]", c.Name, cmd.Name)
+	if cmd.Usage != "" {
+		_, _ = fmt.Fprintf(out, " %s", cmd.Usage)
+	}
+	_, _ = fmt.Fprintln(out)
+	if len(cmd.Aliases) > 0 {
+		_, _ = fmt.Fprintf(out, "Aliases: %s\n", strings.Join(cmd.Aliases, ", "))
+	}
+	if cmd.Summary != "" {
+		_, _ = fmt.Fprintf(out, "\n%s\n", cmd.Summary)
+	}
+	if hasFlags(cmd.Flags) {
+		_, _ = fmt.Fprintln(out, "\nFlags:")
+		cmd.Flags.SetOutput(out)
+		cmd.Flags.PrintDefaults()
+	}
+}
+
+func (c *Commander) output() io.Writer {
+	if c.Output == nil {
+		return os.Stderr
+	}
+	return c.Output
+}
+
+// usageError adds the msg.CodeInvalid code to flag parsing errors other than flag.ErrHelp.
+func usageError(err error) error {
+	if errors.Is(err, flag.ErrHelp) {
+		return err
+	}
+	return msg.WithCode(err, msg.CodeInvalid)
+}
+
+func hasFlags(flagSet *flag.FlagSet) bool {
+	found := false
+	flagSet.VisitAll(func(*flag.Flag) {
+		found = true
+	})
+	return found
+}
+
+// splitCommand returns the index of the command name in the arguments,
+// or the length of the arguments if there is no command name.
+// Arguments before the command name are flags (and their values) defined in the flag set
+// or settings files marked by the prefix, following the same rules as flag.FlagSet.Parse().
+func splitCommand(flagSet *flag.FlagSet, args []string, prefix string) int {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return i + 1
+		} else if len(arg) < 2 || arg[0] != '-' {
+			if strings.HasPrefix(arg, prefix) && len(arg) > len(prefix) {
+				// Settings file.
+				continue
+			}
+			return i
+		}
+		name := strings.TrimLeft(arg, "-")
+		if strings.Contains(name, "=") {
+			continue
+		}
+		if flg := flagSet.Lookup(name); flg != nil {
+			if bf, ok := flg.Value.(interface{ IsBoolFlag() bool }); ok && bf.IsBoolFlag() {
+				continue
+			}
+			// Skip the flag value.
+			i++
+		}
+	}
+	return len(args)
+}
@@ -0,0 +1,193 @@
+package flag
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/madkins23/go-utils/msg"
+)
+
+type commanderTest struct {
+	*Commander
+	output  bytes.Buffer
+	verbose bool
+	out     string
+	level   int
+	ran     string
+	args    []string
+}
+
+func newCommanderTest(t *testing.T) *commanderTest {
+	ct := &commanderTest{Commander: NewCommander("prog")}
+	ct.Output = &ct.output
+	ct.Global.BoolVar(&ct.verbose, "verbose", false, "Verbose output")
+	build := &Command{
+		Name:    "build",
+		Aliases: []string{"make"},
+		Summary: "Build the thing",
+		Usage:   "[target...]",
+		Run: func(cmd *Command, args []string) error {
+			ct.ran = cmd.Name
+			ct.args = args
+			return nil
+		},
+	}
+	require.NoError(t, ct.Add(build))
+	build.Flags.StringVar(&ct.out, "output", "a.out", "Output file")
+	build.Flags.IntVar(&ct.level, "level", 1, "Optimization level")
+	require.NoError(t, ct.Add(&Command{
+		Name:    "bundle",
+		Summary: "Bundle the thing",
+		Run: func(cmd *Command, args []string) error {
+			ct.ran = cmd.Name
+			return msg.WithCode(errors.New("nothing to bundle"), msg.CodeNotFound)
+		},
+	}))
+	return ct
+}
+
+func TestCommander_Add(t *testing.T) {
+	ct := newCommanderTest(t)
+	run := func(*Command, []string) error { return nil }
+	assert.ErrorIs(t, ct.Add(&Command{Name: "x"}), errCommandInvalid)
+	assert.ErrorIs(t, ct.Add(&Command{Run: run}), errCommandInvalid)
+	assert.ErrorIs(t, ct.Add(&Command{Name: "build", Run: run}), errCommandExists)
+	assert.ErrorIs(t, ct.Add(&Command{Name: "other", Aliases: []string{"make"}, Run: run}), errCommandExists)
+}
+
+func TestCommander_Lookup(t *testing.T) {
+	ct := newCommanderTest(t)
+	for name, expected := range map[string]string{
+		"build": "build", "make": "build", "m": "build", "bui": "build", "bun": "bundle", "h": "help",
+	} {
+		cmd, err := ct.Lookup(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, expected, cmd.Name, name)
+	}
+	_, err := ct.Lookup("b")
+	assert.ErrorIs(t, err, errAmbiguous)
+	assert.ErrorContains(t, err, "matches build, bundle")
+	_, err = ct.Lookup("x")
+	assert.ErrorIs(t, err, errUnknownCommand)
+}
+
+func TestCommander_Run(t *testing.T) {
+	ct := newCommanderTest(t)
+	require.NoError(t, ct.Run([]string{"-verbose", "mak", "-output", "x.out", "one", "two"}))
+	assert.True(t, ct.verbose)
+	assert.Equal(t, "build", ct.ran)
+	assert.Equal(t, "x.out", ct.out)
+	assert.Equal(t, []string{"one", "two"}, ct.args)
+	assert.Empty(t, ct.output.String())
+}
+
+func TestCommander_Run_settings(t *testing.T) {
+	ct := newCommanderTest(t)
+	require.NoError(t, ct.Run([]string{
+		"@testdata/commander/global.cfg", "build", "@testdata/commander/build.cfg", "-level", "3"}))
+	assert.True(t, ct.verbose)
+	assert.Equal(t, "from-file.out", ct.out)
+	assert.Equal(t, 3, ct.level, "command line overrides settings file")
+
+	ct = newCommanderTest(t)
+	err := ct.Run([]string{"@testdata/commander/build.cfg", "build"})
+	assert.ErrorContains(t, err, "non-existent flag")
+	assert.Equal(t, msg.CodeInvalid, msg.CodeOf(err))
+}
+
+func TestCommander_Execute(t *testing.T) {
+	ct := newCommanderTest(t)
+	assert.Equal(t, 0, ct.Execute([]string{"build"}))
+	assert.Equal(t, 66, ct.Execute([]string{"bundle"}))
+	assert.Contains(t, ct.output.String(), "prog: nothing to bundle\n")
+
+	ct.output.Reset()
+	assert.Equal(t, 65, ct.Execute([]string{}))
+	assert.Contains(t, ct.output.String(), "Usage: prog")
+	assert.Contains(t, ct.output.String(), "prog: no command specified\n")
+
+	ct.output.Reset()
+	assert.Equal(t, 65, ct.Execute([]string{"b"}))
+	assert.Contains(t, ct.output.String(), "prog: ambiguous command 'b' matches build, bundle\n")
+
+	ct.output.Reset()
+	assert.Equal(t, 65, ct.Execute([]string{"build", "-unknown"}))
+	assert.Contains(t, ct.output.String(), "flag provided but not defined: -unknown")
+
+	ct.output.Reset()
+	assert.Equal(t, 0, ct.Execute([]string{"build", "-help"}))
+	assert.Contains(t, ct.output.String(), "Usage: prog [global flags] build [flags] [target...]")
+}
+
+func TestCommander_help(t *testing.T) {
+	ct := newCommanderTest(t)
+	require.NoError(t, ct.Run([]string{"help"}))
+	assert.Equal(t, ""+
+		"Usage: prog [global flags] <command> [command flags] [arguments]\n"+
+		"\n"+
+		"Commands:\n"+
+		"  build, make  Build the thing\n"+
+		"  bundle       Bundle the thing\n"+
+		"  help         Show help for the program or a command\n"+
+		"\n"+
+		"Global flags:\n"+
+		"  -verbose\n"+
+		"    \tVerbose output\n",
+		ct.output.String())
+
+	ct.output.Reset()
+	require.NoError(t, ct.Run([]string{"help", "make"}))
+	assert.Equal(t, ""+
+		"Usage: prog [global flags] build [flags] [target...]\n"+
+		"Aliases: make\n"+
+		"\n"+
+		"Build the thing\n"+
+		"\n"+
+		"Flags:\n"+
+		"  -level int\n"+
+		"    \tOptimization level (default 1)\n"+
+		"  -output string\n"+
+		"    \tOutput file (default \"a.out\")\n",
+		ct.output.String())
+
+	assert.Error(t, ct.Run([]string{"help", "nothing"}))
+}
+
+func TestSplitCommand(t *testing.T) {
+	flagSet := flag.NewFlagSet("test", flag.ContinueOnError)
+	flagSet.Bool("b", false, "")
+	flagSet.String("s", "", "")
+	assert.Equal(t, 0, splitCommand(flagSet, []string{"cmd", "-b"}, "@"))
+	assert.Equal(t, 1, splitCommand(flagSet, []string{"-b", "cmd"}, "@"))
+	assert.Equal(t, 2, splitCommand(flagSet, []string{"-s", "value", "cmd"}, "@"))
+	assert.Equal(t, 2, splitCommand(flagSet, []string{"--s=value", "@file", "cmd"}, "@"))
+	assert.Equal(t, 2, splitCommand(flagSet, []string{"-b", "--", "cmd"}, "@"))
+	assert.Equal(t, 3, splitCommand(flagSet, []string{"-b", "-s", "value"}, "@"))
+	assert.Equal(t, 1, splitCommand(flagSet, []string{"+file", "cmd"}, "+"))
+}
+
+func ExampleCommander() {
+	commander := NewCommander("example")
+	commander.Output = os.Stdout
+	var name string
+	greet := &Command{
+		Name:    "greet",
+		Summary: "Say hello",
+		Run: func(cmd *Command, args []string) error {
+			fmt.Printf("Hello, %s!\n", name)
+			return nil
+		},
+	}
+	_ = commander.Add(greet)
+	greet.Flags.StringVar(&name, "name", "World", "Name to greet")
+	fmt.Println(commander.Execute([]string{"gr", "-name", "Gopher"}))
+	// Output: Hello, Gopher!
+	// 0
+}
@@ -0,0 +1,264 @@
+package flag
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// Source identifies where the final value of a flag came from.
+type Source uint8
+
+const (
+	// SourceDefault means the flag has its default value.
+	SourceDefault Source = iota
+	// SourceFile means the flag value came from a settings file.
+	SourceFile
+	// SourceEnv means the flag value came from an environment variable.
+	SourceEnv
+	// SourceCommandLine means the flag value came from the command line.
+	SourceCommandLine
+)
+
+var sourceNames = []string{"default", "file", "env", "command line"}
+
+// String returns the name of the source.
+func (s Source) String() string {
+	if int(s) < len(sourceNames) {
+		return sourceNames[s]
+	}
+	return fmt.Sprintf("source(%d)", s)
+}
+
+// ErrShowConfig is returned by Config.Load when the configuration was shown
+// as requested by the ShowFlag flag.
+// Applications will generally exit without error after receiving this.
+var ErrShowConfig = errors.New("configuration shown")
+
+// Config loads flag values from multiple sources with well-defined precedence:
+//
+//	defaults < settings files < environment variables < command line
+//
+// The source of each final flag value is recorded and may be shown via ShowConfig().
+// Flags that accumulate values (e.g. StringArray) collect all values from a single source
+// but values from a higher precedence source replace values from lower precedence sources.
+// This applies to the accumulating flag types in this package,
+// other flag.Value types collect values from all sources.
+type Config struct {
+	// EnvPrefix enables automatic environment variable names if not empty.
+	// Each flag name is converted to upper case with non-alphanumeric characters
+	// replaced by underscores and prefixed with EnvPrefix and an underscore,
+	// e.g. the flag 'server.port' with prefix 'APP' becomes 'APP_SERVER_PORT'.
+	EnvPrefix string
+
+	// EnvNames maps flag names to environment variable names.
+	// Explicit names take precedence over automatic names.
+	// An empty environment variable name disables the environment for that flag.
+	EnvNames map[string]string
+
+	// ShowFlag is the name of a boolean flag to be defined by Load (e.g. "show-config").
+	// If the flag is set Load writes the configuration to the flag set output
+	// and returns ErrShowConfig.
+	ShowFlag string
+
+	// SettingsOptions configure the loading of settings files as for LoadSettingsFromArgs.
+	SettingsOptions []SettingsOption
+
+	sources map[string]Source
+	origins map[string]string
+	flagSet *flag.FlagSet
+}
+
+// resetter is implemented by flag values that accumulate values
+// so that values from lower precedence sources can be removed.
+type resetter interface {
+	reset()
+}
+
+// Bind registers flags for the fields of the configuration struct as for Bind.
+// Environment variables from env tags are added to EnvNames (unless already present)
+// and applied by Load with the same precedence as other environment variables
+// instead of being applied immediately.
+func (c *Config) Bind(flagSet *flag.FlagSet, cfg interface{}) error {
+	return bind(flagSet, cfg, func(name, envName string) {
+		if c.EnvNames == nil {
+			c.EnvNames = make(map[string]string)
+		}
+		if _, found := c.EnvNames[name]; !found {
+			c.EnvNames[name] = envName
+		}
+	})
+}
+
+// EnvName returns the environment variable name for the specified flag name
+// or an empty string if the flag has no environment variable.
+func (c *Config) EnvName(name string) string {
+	if envName, found := c.EnvNames[name]; found {
+		return envName
+	} else if c.EnvPrefix == "" {
+		return ""
+	}
+	return c.EnvPrefix + "_" + strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, name)
+}
+
+// Load sets flag values in the flag set from all sources and parses the command line arguments.
+// Settings files are specified in the arguments as '@<path>' as for LoadSettingsFromArgs.
+// The arguments should not include the program name (e.g. os.Args[1:]).
+// Remaining arguments are available from the flag set after Load returns.
+func (c *Config) Load(flagSet *flag.FlagSet, args []string) error {
+	c.flagSet = flagSet
+	c.sources = make(map[string]Source)
+	c.origins = make(map[string]string)
+
+	var show bool
+	if c.ShowFlag != "" {
+		flagSet.BoolVar(&show, c.ShowFlag, false, "Show configuration values and their sources")
+	}
+
+	options := append([]SettingsOption{}, c.SettingsOptions...)
+	options = append(options, withApplied(func(name, file string) {
+		c.record(name, SourceFile, file)
+	}))
+	fixedArgs, err := LoadSettingsFromArgs(flagSet, args, options...)
+	if err != nil {
+		return err
+	}
+
+	if err := c.loadEnv(flagSet); err != nil {
+		return fmt.Errorf("load environment: %w", err)
+	}
+
+	for name := range commandLineFlags(flagSet, fixedArgs) {
+		c.reset(flagSet.Lookup(name))
+	}
+	if err = flagSet.Parse(fixedArgs); err != nil {
+		return err
+	}
+	flagSet.Visit(func(flg *flag.Flag) {
+		c.record(flg.Name, SourceCommandLine, "")
+	})
+
+	if show {
+		c.ShowConfig(flagSet.Output())
+		return ErrShowConfig
+	}
+	return nil
+}
+
+// loadEnv sets flag values from environment variables.
+func (c *Config) loadEnv(flagSet *flag.FlagSet) error {
+	errs := make([]error, 0)
+	flagSet.VisitAll(func(flg *flag.Flag) {
+		envName := c.EnvName(flg.Name)
+		if envName == "" {
+			return
+		}
+		if value, found := os.LookupEnv(envName); found {
+			c.reset(flg)
+			if err := flg.Value.Set(value); err != nil {
+				errs = append(errs, fmt.Errorf("set flag '%s' from %s to '%s': %w", flg.Name, envName, value, err))
+				return
+			}
+			flg.DefValue = value
+			c.record(flg.Name, SourceEnv, envName)
+		}
+	})
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+// reset removes the value of an accumulating flag set from a lower precedence source.
+func (c *Config) reset(flg *flag.Flag) {
+	if _, found := c.sources[flg.Name]; found {
+		if value, ok := flg.Value.(resetter); ok {
+			value.reset()
+		}
+	}
+}
+
+// commandLineFlags returns the names of the flags in the flag set that are set by the arguments.
+// The arguments are parsed by a scratch flag set so that no flag values are changed.
+// Parse errors are ignored, they are reported when the arguments are parsed by the flag set.
+func commandLineFlags(flagSet *flag.FlagSet, args []string) map[string]bool {
+	scratch := flag.NewFlagSet(flagSet.Name(), flag.ContinueOnError)
+	scratch.SetOutput(io.Discard)
+	scratch.Usage = func() {}
+	flagSet.VisitAll(func(flg *flag.Flag) {
+		bf, ok := flg.Value.(interface{ IsBoolFlag() bool })
+		scratch.Var(&scratchValue{isBool: ok && bf.IsBoolFlag()}, flg.Name, "")
+	})
+	_ = scratch.Parse(args)
+	names := make(map[string]bool)
+	scratch.Visit(func(flg *flag.Flag) {
+		names[flg.Name] = true
+	})
+	return names
+}
+
+// scratchValue is a flag value that ignores its values.
+type scratchValue struct {
+	isBool bool
+}
+
+func (sv *scratchValue) String() string {
+	return ""
+}
+
+func (sv *scratchValue) Set(string) error {
+	return nil
+}
+
+func (sv *scratchValue) IsBoolFlag() bool {
+	return sv.isBool
+}
+
+// record the source of a flag value.
+func (c *Config) record(name string, source Source, origin string) {
+	c.sources[name] = source
+	c.origins[name] = origin
+}
+
+// Source returns the source of the final value of the specified flag
+// and the origin of the value, which is the settings file path or
+// environment variable name if applicable.
+func (c *Config) Source(name string) (Source, string) {
+	return c.sources[name], c.origins[name]
+}
+
+// ShowConfig writes the value and source of each flag to the writer, sorted by flag name.
+// Must be called after Load.
+func (c *Config) ShowConfig(w io.Writer) {
+	if c.flagSet == nil {
+		return
+	}
+	names := make([]string, 0)
+	width := 0
+	// VisitAll visits flags in lexicographical order.
+	c.flagSet.VisitAll(func(flg *flag.Flag) {
+		if flg.Name != c.ShowFlag {
+			names = append(names, flg.Name)
+			if len(flg.Name) > width {
+				width = len(flg.Name)
+			}
+		}
+	})
+	for _, name := range names {
+		source, origin := c.Source(name)
+		if origin != "" {
+			origin = " " + origin
+		}
+		_, _ = fmt.Fprintf(w, "%-*s = %s (%s%s)\n",
+			width, name, c.flagSet.Lookup(name).Value.String(), source, origin)
+	}
+}
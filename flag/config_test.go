@@ -0,0 +1,207 @@
+package flag
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSource_String(t *testing.T) {
+	assert.Equal(t, "default", SourceDefault.String())
+	assert.Equal(t, "command line", SourceCommandLine.String())
+	assert.Equal(t, "source(9)", Source(9).String())
+}
+
+func TestConfig_EnvName(t *testing.T) {
+	var config Config
+	assert.Empty(t, config.EnvName("server.port"))
+	config.EnvPrefix = "APP"
+	assert.Equal(t, "APP_SERVER_PORT", config.EnvName("server.port"))
+	assert.Equal(t, "APP_LOG_FILE", config.EnvName("log-file"))
+	config.EnvNames = map[string]string{"text": "TEXT", "whole": ""}
+	assert.Equal(t, "TEXT", config.EnvName("text"))
+	assert.Empty(t, config.EnvName("whole"))
+}
+
+func TestConfig_Load(t *testing.T) {
+	t.Setenv("TEST_CONFIG_WHOLE", "19")
+	t.Setenv("TEST_CONFIG_TEXT", "From the environment")
+	t.Setenv("FLOATING", "0.5")
+	var (
+		text  string
+		whole int
+		float float64
+		other string
+	)
+	flagSet := makeFlagSet(&text, &whole, &float)
+	flagSet.StringVar(&other, "other", "Other", "Other string")
+	config := &Config{
+		EnvPrefix: "TEST_CONFIG",
+		EnvNames:  map[string]string{"float": "FLOATING"},
+	}
+	require.NoError(t, config.Load(flagSet, []string{"@testdata/settings.json", "-text", "Read Me!", "extra"}))
+	assert.Equal(t, "Read Me!", text, "command line overrides environment")
+	assert.Equal(t, 19, whole, "environment overrides default")
+	assert.Equal(t, 0.5, float, "environment overrides file")
+	assert.Equal(t, "Other", other)
+	assert.Equal(t, []string{"extra"}, flagSet.Args())
+
+	source, origin := config.Source("text")
+	assert.Equal(t, SourceCommandLine, source)
+	assert.Empty(t, origin)
+	source, origin = config.Source("whole")
+	assert.Equal(t, SourceEnv, source)
+	assert.Equal(t, "TEST_CONFIG_WHOLE", origin)
+	source, origin = config.Source("float")
+	assert.Equal(t, SourceEnv, source)
+	assert.Equal(t, "FLOATING", origin)
+	source, origin = config.Source("other")
+	assert.Equal(t, SourceDefault, source)
+	assert.Empty(t, origin)
+
+	flagSet = makeFlagSet(&text, &whole, &float)
+	config = &Config{}
+	require.NoError(t, config.Load(flagSet, []string{"@testdata/settings.json"}))
+	assert.Equal(t, "Don't Look!", text)
+	source, origin = config.Source("text")
+	assert.Equal(t, SourceFile, source)
+	assert.Equal(t, "testdata/settings.json", origin)
+}
+
+func TestConfig_Load_accumulating(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "tags.cfg")
+	require.NoError(t, os.WriteFile(file, []byte("tag = a\ntag = b\nlabel = k:file\n"), 0o600))
+	load := func(args ...string) (StringArray, StringMap) {
+		var tags StringArray
+		var labels StringMap
+		flagSet := newFlagSet()
+		flagSet.Var(&tags, "tag", "Tags")
+		flagSet.Var(&labels, "label", "Labels")
+		require.NoError(t, (&Config{EnvPrefix: "TEST_CONFIG"}).Load(flagSet, append([]string{"@" + file}, args...)))
+		return tags, labels
+	}
+
+	tags, labels := load("-tag", "x")
+	assert.Equal(t, StringArray{"x"}, tags, "command line replaces file")
+	assert.Equal(t, StringMap{"k": "file"}, labels)
+
+	t.Setenv("TEST_CONFIG_TAG", "env1,env2")
+	t.Setenv("TEST_CONFIG_LABEL", "j:env")
+	tags, labels = load()
+	assert.Equal(t, StringArray{"env1", "env2"}, tags, "environment replaces file")
+	assert.Equal(t, StringMap{"j": "env"}, labels)
+	tags, labels = load("-tag", "x", "-tag", "y", "-label", "i:cli")
+	assert.Equal(t, StringArray{"x", "y"}, tags, "command line replaces environment")
+	assert.Equal(t, StringMap{"i": "cli"}, labels)
+}
+
+func TestConfig_Bind(t *testing.T) {
+	t.Setenv("TEST_CONFIG_BIND_TEXT", "From the environment")
+	var cfg struct {
+		Text  string  `flag:"text" env:"TEST_CONFIG_BIND_TEXT"`
+		Whole int     `flag:"whole" env:"TEST_CONFIG_BIND_WHOLE"`
+		Float float64 `flag:"float" env:"TEST_CONFIG_BIND_FLOAT"`
+	}
+	flagSet := newFlagSet()
+	config := &Config{EnvNames: map[string]string{"float": ""}}
+	require.NoError(t, config.Bind(flagSet, &cfg))
+	assert.Empty(t, cfg.Text, "environment not applied by Bind")
+	assert.Equal(t, map[string]string{
+		"text": "TEST_CONFIG_BIND_TEXT", "whole": "TEST_CONFIG_BIND_WHOLE", "float": ""}, config.EnvNames)
+
+	t.Setenv("TEST_CONFIG_BIND_FLOAT", "0.5")
+	require.NoError(t, config.Load(flagSet, []string{"@testdata/settings.json"}))
+	assert.Equal(t, "From the environment", cfg.Text, "environment overrides file")
+	assert.Equal(t, 2.71828, cfg.Float, "explicit EnvNames override env tags")
+	source, origin := config.Source("text")
+	assert.Equal(t, SourceEnv, source)
+	assert.Equal(t, "TEST_CONFIG_BIND_TEXT", origin)
+}
+
+func TestConfig_Bind_accumulate(t *testing.T) {
+	load := func(args ...string) *bindAccumulate {
+		cfg := &bindAccumulate{}
+		flagSet := newFlagSet()
+		config := &Config{}
+		require.NoError(t, config.Bind(flagSet, cfg))
+		require.NoError(t, config.Load(flagSet, args))
+		return cfg
+	}
+	cfg := load()
+	assert.Equal(t, StringArray{"a", "b"}, cfg.Tags)
+	assert.Equal(t, StringMap{"i": "1"}, cfg.Labels)
+	cfg = load("-tags", "d", "-labels", "l:4")
+	assert.Equal(t, StringArray{"d"}, cfg.Tags, "command line replaces default")
+	assert.Equal(t, StringMap{"l": "4"}, cfg.Labels)
+
+	t.Setenv("TEST_BIND_TAGS", "c")
+	t.Setenv("TEST_BIND_LABELS", "k:3")
+	cfg = load()
+	assert.Equal(t, StringArray{"c"}, cfg.Tags, "environment replaces default")
+	assert.Equal(t, StringMap{"k": "3"}, cfg.Labels)
+	cfg = load("-tags", "d", "-tags", "e", "-labels", "l:4")
+	assert.Equal(t, StringArray{"d", "e"}, cfg.Tags, "command line replaces environment")
+	assert.Equal(t, StringMap{"l": "4"}, cfg.Labels)
+}
+
+func TestConfig_Load_errors(t *testing.T) {
+	t.Setenv("TEST_CONFIG_WHOLE", "many")
+	var (
+		text  string
+		whole int
+		float float64
+	)
+	config := &Config{EnvPrefix: "TEST_CONFIG"}
+	err := config.Load(makeFlagSet(&text, &whole, &float), nil)
+	assert.ErrorContains(t, err, "set flag 'whole' from TEST_CONFIG_WHOLE to 'many'")
+	err = (&Config{}).Load(makeFlagSet(&text, &whole, &float), []string{"@testdata/noSuchFile.json"})
+	assert.ErrorContains(t, err, "load settings file")
+	flagSet := makeFlagSet(&text, &whole, &float)
+	flagSet.SetOutput(&bytes.Buffer{})
+	assert.Error(t, (&Config{}).Load(flagSet, []string{"-unknown"}))
+}
+
+func TestConfig_ShowConfig(t *testing.T) {
+	t.Setenv("TEST_CONFIG_WHOLE", "19")
+	var (
+		text  string
+		whole int
+		float float64
+	)
+	flagSet := makeFlagSet(&text, &whole, &float)
+	var output bytes.Buffer
+	flagSet.SetOutput(&output)
+	config := &Config{EnvPrefix: "TEST_CONFIG", ShowFlag: "show-config"}
+	err := config.Load(flagSet, []string{"@testdata/settings.cfg", "-float", "3", "-show-config"})
+	assert.ErrorIs(t, err, ErrShowConfig)
+	assert.Equal(t, ""+
+		"float = 3 (command line)\n"+
+		"text  = Forgotten! (file testdata/settings.cfg)\n"+
+		"whole = 19 (env TEST_CONFIG_WHOLE)\n",
+		output.String())
+}
+
+func ExampleConfig() {
+	_ = os.Setenv("EXAMPLE_PORT", "8080")
+	defer func() {
+		_ = os.Unsetenv("EXAMPLE_PORT")
+	}()
+	var host string
+	var port int
+	flagSet := flag.NewFlagSet("example", flag.ContinueOnError)
+	flagSet.StringVar(&host, "host", "localhost", "Server host")
+	flagSet.IntVar(&port, "port", 80, "Server port")
+	config := &Config{EnvPrefix: "EXAMPLE"}
+	if err := config.Load(flagSet, []string{"-host", "example.com"}); err != nil {
+		fmt.Println(err)
+	}
+	config.ShowConfig(os.Stdout)
+	// Output: host = example.com (command line)
+	// port = 8080 (env EXAMPLE_PORT)
+}
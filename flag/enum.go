@@ -0,0 +1,62 @@
+package flag
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Enum defines a flag with a string value restricted to a set of allowed values.
+// Construct Enum objects using NewEnum().
+type Enum struct {
+	// Value of the flag, initially the default value.
+	Value         string
+	allowed       []string
+	caseSensitive bool
+}
+
+// NewEnum returns a new Enum with the specified default value and allowed values.
+// Values are matched without regard to case and stored as specified in the allowed list.
+// The default value need not be one of the allowed values (e.g. it may be empty).
+func NewEnum(defaultValue string, allowed ...string) *Enum {
+	return &Enum{
+		Value:   defaultValue,
+		allowed: allowed,
+	}
+}
+
+// CaseSensitive configures the Enum to match values with regard to case.
+// Returns the Enum so that it can be used in a flag.Var() call.
+func (e *Enum) CaseSensitive() *Enum {
+	e.caseSensitive = true
+	return e
+}
+
+// Allowed returns the allowed values.
+func (e *Enum) Allowed() []string {
+	return e.allowed
+}
+
+// String representation of the flag value.
+func (e *Enum) String() string {
+	if e == nil {
+		return ""
+	}
+	return e.Value
+}
+
+// Set the flag value, which must be one of the allowed values.
+func (e *Enum) Set(value string) error {
+	for _, allowed := range e.allowed {
+		if value == allowed || !e.caseSensitive && strings.EqualFold(value, allowed) {
+			e.Value = allowed
+			return nil
+		}
+	}
+	return fmt.Errorf("%q is not one of %s", value, strings.Join(e.allowed, ", "))
+}
+
+// Get returns the flag value as a string.
+// Implements the flag.Getter interface.
+func (e *Enum) Get() interface{} {
+	return e.Value
+}
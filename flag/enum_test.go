@@ -0,0 +1,35 @@
+package flag
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnum(t *testing.T) {
+	level := NewEnum("info", "debug", "info", "warn", "error")
+	flags := newFlagSet()
+	flags.Var(level, "level", "Some description for this param.")
+	require.NoError(t, flags.Parse([]string{}))
+	assert.Equal(t, "info", level.Value)
+	require.NoError(t, flags.Parse([]string{"-level", "WARN"}))
+	assert.Equal(t, "warn", level.Value)
+	assert.Equal(t, "warn", level.String())
+	assert.Equal(t, "warn", level.Get())
+	assert.Equal(t, []string{"debug", "info", "warn", "error"}, level.Allowed())
+
+	err := flags.Parse([]string{"-level", "fatal"})
+	require.Error(t, err)
+	assert.Equal(t,
+		`invalid value "fatal" for flag -level: "fatal" is not one of debug, info, warn, error`,
+		err.Error())
+	assert.Equal(t, "warn", level.Value)
+}
+
+func TestEnum_CaseSensitive(t *testing.T) {
+	mode := NewEnum("", "A", "a").CaseSensitive()
+	require.NoError(t, mode.Set("a"))
+	assert.Equal(t, "a", mode.Value)
+	assert.Error(t, NewEnum("", "A").CaseSensitive().Set("a"))
+}
@@ -1,13 +1,11 @@
 package flag
 
 import (
-	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
 
 	"github.com/madkins23/go-utils/path"
@@ -16,68 +14,168 @@ import (
 // LoadSettings overrides default values in the specified flag.FlagSet with
 // values taken from a settings file(s) specified as '@<path>' on the command line.
 // All settings file specifications are removed from os.Args by this function.
-// Each settings file is unmarshaled into a map[string]string:
-// JSON files contain legitimate JSON, CFG files contain 'key = value' lines.
-// Each key in the map is looked up in the specified flagSet and its default value
+// The type of each settings file is determined by its extension:
+// JSON (.json), YAML (.yaml or .yml), TOML (.toml), INI (.ini) or CFG (.cfg).
+// JSON, YAML and TOML files may contain nested maps which are flattened
+// to dotted flag names (e.g. 'server.port'), arrays which set a flag once per element
+// (e.g. for StringArray flags), and non-string scalar values.
+// CFG and INI files contain 'key = value' lines, comments, and '[section]' prefixes (see parseConfig).
+// Each key is looked up in the specified flagSet and its default value
 // and current value replaced by the value associated with that key from the settings file.
 // After this the flagSet can be parsed so that the flag values from the command
 // line override the defaults from the settings file as needed.
 // Order of settings files is important, later ones override earlier ones.
 // Flags remaining in os.Args can then be processed in the normal manner.
+//
+// See LoadSettingsFromArgs for a version that does not use os.Args.
+// See SaveSettings for writing settings files.
 func LoadSettings(flagSet *flag.FlagSet) error {
-	fixedArgs := make([]string, 0, len(os.Args))
-	for _, arg := range os.Args {
-		if !strings.HasPrefix(arg, "@") {
-			fixedArgs = append(fixedArgs, arg)
-		} else if err := loadSettingsFile(flagSet, arg[1:]); err != nil {
-			return fmt.Errorf("load settings file: %w", err)
-		}
+	args, err := LoadSettingsFromArgs(flagSet, os.Args)
+	if err != nil {
+		return err
 	}
-	os.Args = fixedArgs
+	os.Args = args
 
 	return nil
 }
 
-var errEmptyPath = errors.New("empty path")
+// SettingsOption configures LoadSettingsFromArgs.
+type SettingsOption func(*settingsLoader)
+
+// WithPrefix sets the character that marks a settings file argument, by default '@'.
+// The same character prefixes the include directive in settings files.
+func WithPrefix(prefix rune) SettingsOption {
+	return func(loader *settingsLoader) {
+		loader.prefix = string(prefix)
+	}
+}
+
+// WithSearchPath sets directories to be searched in order for relative settings file paths
+// that are not found relative to the current directory (or the including file).
+func WithSearchPath(dirs ...string) SettingsOption {
+	return func(loader *settingsLoader) {
+		loader.searchPath = dirs
+	}
+}
+
+// withApplied sets a function to be called with the name of each flag set from a settings file.
+func withApplied(applied func(name, file string)) SettingsOption {
+	return func(loader *settingsLoader) {
+		loader.applied = applied
+	}
+}
+
+// LoadSettingsFromArgs overrides default values in the specified flag.FlagSet with
+// values taken from a settings file(s) specified as '@<path>' in the specified arguments
+// and returns the remaining arguments, which can then be parsed by the flagSet.
+// The arguments are not modified.
+// Settings files are processed as described for LoadSettings.
+//
+// Settings files may include other settings files using the '@include' key
+// with a single path or an array of paths as the value (e.g. '@include = common.cfg').
+// Included files are loaded before the other settings in the including file so that
+// the including file can override them.
+// Relative include paths are resolved relative to the including file.
+// Cycles of included files are reported as errors.
+func LoadSettingsFromArgs(flagSet *flag.FlagSet, args []string, options ...SettingsOption) ([]string, error) {
+	loader := newSettingsLoader(flagSet, options)
+
+	remaining := make([]string, 0, len(args))
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, loader.prefix) {
+			remaining = append(remaining, arg)
+		} else if err := loader.load(strings.TrimPrefix(arg, loader.prefix), ""); err != nil {
+			return nil, fmt.Errorf("load settings file: %w", err)
+		}
+	}
+
+	return remaining, nil
+}
+
+var (
+	errEmptyPath    = errors.New("empty path")
+	errIncludeCycle = errors.New("include cycle")
+)
 
-func loadSettingsFile(flagSet *flag.FlagSet, file string) error {
+// settingsLoader loads settings files into a flag set.
+type settingsLoader struct {
+	flagSet    *flag.FlagSet
+	prefix     string
+	searchPath []string
+	applied    func(name, file string)
+	loading    []string
+}
+
+// newSettingsLoader returns a settingsLoader for the flag set configured by the options.
+func newSettingsLoader(flagSet *flag.FlagSet, options []SettingsOption) *settingsLoader {
+	loader := &settingsLoader{
+		flagSet: flagSet,
+		prefix:  "@",
+	}
+	for _, option := range options {
+		option(loader)
+	}
+	return loader
+}
+
+// load sets flag values from the specified settings file.
+// Relative paths are resolved against the specified directory (if any) and the search path.
+func (l *settingsLoader) load(file, dir string) error {
 	if file == "" {
 		return errEmptyPath
 	}
 
-	// TODO: Handle different types of files (not just JSON)
-	var settings map[string]string
-	if fixed, err := path.FixHomePath(file); err != nil {
-		return fmt.Errorf("fix path '%s': %w", file, err)
-	} else if bytes, err := os.ReadFile(fixed); err != nil {
+	var settings map[string][]string
+	var arrays map[string]bool
+	fixed, err := l.resolve(file, dir)
+	if err != nil {
+		return err
+	}
+	for _, loading := range l.loading {
+		if loading == fixed {
+			return fmt.Errorf("%w: %s -> %s", errIncludeCycle, strings.Join(l.loading, " -> "), fixed)
+		}
+	}
+	if bytes, err := os.ReadFile(fixed); err != nil {
 		return fmt.Errorf("read file '%s': %w", fixed, err)
-	} else {
-		var ext = filepath.Ext(file)
-		switch strings.ToLower(ext) {
-		case ".json":
-			if err = json.Unmarshal(bytes, &settings); err != nil {
-				return fmt.Errorf("unmarshal JSON settings: %w", err)
-			}
-		case ".cfg":
-			if settings, err = configUnmarshal(string(bytes)); err != nil {
-				return fmt.Errorf("unmarshal CFG settings: %w", err)
+	} else if settings, arrays, err = unmarshalSettings(filepath.Ext(file), bytes); err != nil {
+		return err
+	}
+
+	include := l.prefix + "include"
+	if includes, found := settings[include]; found {
+		l.loading = append(l.loading, fixed)
+		for _, included := range includes {
+			if err := l.load(included, filepath.Dir(fixed)); err != nil {
+				return fmt.Errorf("include '%s': %w", included, err)
 			}
-		default:
-			return fmt.Errorf("unknown settings file extension '%s'", ext)
 		}
+		l.loading = l.loading[:len(l.loading)-1]
+		delete(settings, include)
 	}
 
 	errs := make([]error, 0)
-	for key, value := range settings {
+	for key, values := range settings {
+		array := arrays[key]
 		for strings.HasPrefix(key, "-") {
 			key = key[1:]
 		}
-		if flg := flagSet.Lookup(key); flg == nil {
+		if flg := l.flagSet.Lookup(key); flg == nil {
 			errs = append(errs, fmt.Errorf("setting referenced non-existent flag '%s'", key))
 		} else {
-			flg.DefValue = value
-			if err := flg.Value.Set(value); err != nil {
-				errs = append(errs, fmt.Errorf("set value of flag to '%s': %w", value, err))
+			separator, splits := splitSeparator(flg.Value)
+			for _, value := range values {
+				if splits && array {
+					// Each array element is a single item.
+					value = quoteItem(value, separator)
+				}
+				if err := flg.Value.Set(value); err != nil {
+					errs = append(errs, fmt.Errorf("set value of flag to '%s': %w", value, err))
+				}
+			}
+			flg.DefValue = flg.Value.String()
+			if l.applied != nil {
+				l.applied(flg.Name, file)
 			}
 		}
 	}
@@ -88,20 +186,26 @@ func loadSettingsFile(flagSet *flag.FlagSet, file string) error {
 	return nil
 }
 
-var errBadRegex = errors.New("unable to compile regex")
-
-func configUnmarshal(text string) (map[string]string, error) {
-	if re, err := regexp.Compile("^\\s*(\\S+)\\s*=\\s*(.*)\\s*$"); err != nil {
-		return nil, errBadRegex
-	} else {
-		settings := make(map[string]string)
-		for _, line := range strings.Split(text, "\n") {
-			if subMatches := re.FindStringSubmatch(line); subMatches != nil {
-				settings[subMatches[1]] = subMatches[2]
-			} else if strings.Trim(line, " \t") != "" {
-				return nil, fmt.Errorf("unknown config line '%s'", line)
-			}
+// resolve returns the absolute path of a settings file.
+// Relative paths are tried relative to the specified directory if not empty
+// (otherwise the current directory) and then relative to each search path directory.
+// If the file isn't found the first candidate is returned so that the error refers to it.
+func (l *settingsLoader) resolve(file, dir string) (string, error) {
+	fixed, err := path.FixHomePath(file)
+	if err != nil {
+		return "", fmt.Errorf("fix path '%s': %w", file, err)
+	}
+	if filepath.IsAbs(fixed) {
+		return fixed, nil
+	}
+	candidates := []string{filepath.Join(dir, fixed)}
+	for _, searchDir := range l.searchPath {
+		candidates = append(candidates, filepath.Join(searchDir, fixed))
+	}
+	for _, candidate := range candidates {
+		if _, err := os.Stat(candidate); err == nil {
+			return filepath.Abs(candidate)
 		}
-		return settings, nil
 	}
+	return filepath.Abs(candidates[0])
 }
@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -127,6 +128,168 @@ func TestLoadSettings_badValue(t *testing.T) {
 	assert.Error(t, LoadSettings(flagSet))
 }
 
+func TestLoadSettings_nested(t *testing.T) {
+	for ext, expected := range map[string]struct {
+		text  string
+		whole int
+	}{
+		"json": {"Nested JSON", 37},
+		"yaml": {"Nested YAML", 29},
+		"toml": {"Nested TOML", 31},
+		"ini":  {"Nested INI", 41},
+	} {
+		t.Run(ext, func(t *testing.T) {
+			var (
+				text    string
+				whole   int
+				float   float64
+				verbose bool
+				tags    StringArray
+				host    string
+				port    int
+			)
+			flagSet := makeFlagSet(&text, &whole, &float)
+			flagSet.BoolVar(&verbose, "verbose", false, "Verbose")
+			flagSet.Var(&tags, "tag", "Tags")
+			flagSet.StringVar(&host, "server.host", "localhost", "Server host")
+			flagSet.IntVar(&port, "server.port", 80, "Server port")
+			os.Args = []string{"path", "@testdata/nested." + ext}
+			require.NoError(t, LoadSettings(flagSet))
+			require.NoError(t, flagSet.Parse(os.Args[1:]))
+			assert.Equal(t, expected.text, text)
+			assert.Equal(t, expected.whole, whole, "integer scalar")
+			assert.Equal(t, 0.57721, float)
+			assert.True(t, verbose)
+			assert.Equal(t, StringArray{"alpha", "bravo"}, tags)
+			assert.Equal(t, "[alpha,bravo]", flagSet.Lookup("tag").DefValue)
+			assert.Equal(t, "example.com", host)
+			assert.Equal(t, 8080, port)
+		})
+	}
+}
+
+func TestUnmarshalSettings(t *testing.T) {
+	settings, _, err := unmarshalSettings(".json", []byte(`{"big": 12345678901234567890, "none": null}`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"big": {"12345678901234567890"}, "none": {""}}, settings)
+	settings, _, err = unmarshalSettings(".YML", []byte("a:\n  b:\n    c: 1.5\n"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"a.b.c": {"1.5"}}, settings)
+	settings, _, err = unmarshalSettings(".toml", []byte("when = 2024-01-02T03:04:05Z"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"when": {"2024-01-02T03:04:05Z"}}, settings)
+	settings, arrays, err := unmarshalSettings(".yaml", []byte("tags: [a, b]\nname: c, d\n"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"tags": {"a", "b"}, "name": {"c, d"}}, settings)
+	assert.Equal(t, map[string]bool{"tags": true}, arrays)
+
+	_, _, err = unmarshalSettings(".json", []byte(`{"array": [{"a": 1}]}`))
+	assert.ErrorContains(t, err, "array 'array': unsupported value type map[string]interface {}")
+	_, _, err = unmarshalSettings(".ini", []byte("[section]\nno equals sign"))
+	assert.ErrorContains(t, err, "unmarshal INI settings: line 2: missing '=' in 'no equals sign'")
+	_, _, err = unmarshalSettings(".yaml", []byte("- not\n- a map"))
+	assert.Error(t, err)
+	_, _, err = unmarshalSettings(".toml", []byte("= bad"))
+	assert.Error(t, err)
+	_, _, err = unmarshalSettings(".xml", nil)
+	assert.ErrorContains(t, err, "unknown settings file extension '.xml'")
+}
+
+func TestLoadSettingsFromArgs(t *testing.T) {
+	t.Parallel()
+	var (
+		text  string
+		whole int
+		float float64
+	)
+	args := []string{"path", "@testdata/settings.cfg", "-float", "3.14159", "extra"}
+	flagSet := makeFlagSet(&text, &whole, &float)
+	remaining, err := LoadSettingsFromArgs(flagSet, args)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"path", "-float", "3.14159", "extra"}, remaining)
+	assert.Equal(t, "@testdata/settings.cfg", args[1], "arguments not modified")
+	require.NoError(t, flagSet.Parse(remaining[1:]))
+	assert.Equal(t, "Forgotten!", text)
+	assert.Equal(t, 17, whole)
+	assert.Equal(t, 3.14159, float)
+	assert.Equal(t, []string{"extra"}, flagSet.Args())
+}
+
+func TestLoadSettingsFromArgs_options(t *testing.T) {
+	t.Parallel()
+	var (
+		text  string
+		whole int
+		float float64
+	)
+	flagSet := makeFlagSet(&text, &whole, &float)
+	remaining, err := LoadSettingsFromArgs(flagSet,
+		[]string{"@literal", "+searched.cfg", "+testdata/include/plus.cfg"},
+		WithPrefix('+'), WithSearchPath("testdata/noSuchDirectory", "testdata/search"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"@literal"}, remaining)
+	assert.Equal(t, "Common", text, "included by later file")
+	assert.Equal(t, 43, whole)
+	assert.Equal(t, 2.5, float, "including file overrides included file")
+
+	_, err = LoadSettingsFromArgs(flagSet, []string{"+noSuchFile.cfg"},
+		WithPrefix('+'), WithSearchPath("testdata/search"))
+	assert.ErrorContains(t, err, "noSuchFile.cfg")
+}
+
+func TestLoadSettingsFromArgs_arrays(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	load := func(file, content string) (StringArray, *flag.FlagSet) {
+		path := filepath.Join(dir, file)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		var tags StringArray
+		var name string
+		flagSet := newFlagSet()
+		flagSet.Var(&tags, "tags", "Tags")
+		flagSet.StringVar(&name, "name", "", "Name")
+		_, err := LoadSettingsFromArgs(flagSet, []string{"@" + path})
+		require.NoError(t, err)
+		return tags, flagSet
+	}
+	tags, flagSet := load("items.yaml", "tags: [\"a, b\", c]\n")
+	assert.Equal(t, StringArray{"a, b", "c"}, tags, "array elements are not split")
+	assert.Equal(t, "[a, b,c]", flagSet.Lookup("tags").DefValue)
+	tags, _ = load("single.json", `{"tags": ["a, b"]}`)
+	assert.Equal(t, StringArray{"a, b"}, tags)
+	tags, _ = load("scalar.toml", `tags = "a, b"`)
+	assert.Equal(t, StringArray{"a", "b"}, tags, "scalar values are split")
+	tags, _ = load("repeated.cfg", "tags = a, b\ntags = c\n")
+	assert.Equal(t, StringArray{"a", "b", "c"}, tags, "configuration values are split")
+
+	_, flagSet = load("name.yaml", "name: [x, y]\n")
+	assert.Equal(t, "y", flagSet.Lookup("name").Value.String())
+	assert.Equal(t, "y", flagSet.Lookup("name").DefValue, "default is the final value")
+	_, flagSet = load("name.ini", "name = x\nname = y\n")
+	assert.Equal(t, "y", flagSet.Lookup("name").DefValue)
+}
+
+func TestLoadSettingsFromArgs_include(t *testing.T) {
+	t.Parallel()
+	var (
+		text  string
+		whole int
+		float float64
+	)
+	flagSet := makeFlagSet(&text, &whole, &float)
+	_, err := LoadSettingsFromArgs(flagSet, []string{"@testdata/include/main.cfg"})
+	require.NoError(t, err)
+	assert.Equal(t, "Included main", text)
+	assert.Equal(t, 43, whole)
+	assert.Equal(t, 1.5, float)
+
+	_, err = LoadSettingsFromArgs(flagSet, []string{"@testdata/include/cycle-a.json"})
+	assert.ErrorIs(t, err, errIncludeCycle)
+	assert.ErrorContains(t, err, "cycle-a.json -> ")
+	_, err = LoadSettingsFromArgs(flagSet, []string{"@"})
+	assert.ErrorIs(t, err, errEmptyPath)
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 
 func ExampleLoadSettings() {
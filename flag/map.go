@@ -0,0 +1,76 @@
+package flag
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Map defines a flag that can be invoked multiple times with typed key/value pairs accumulated in a map.
+// Each flag value is broken at its first embedded colon to create a key/value pair as with StringMap.
+// Keys and values are converted by parse functions and values are checked by any validators.
+// Construct Map objects using NewMap().
+type Map[K comparable, V any] struct {
+	// Values accumulated from flag arguments.
+	Values     map[K]V
+	parseKey   ParseFn[K]
+	parseValue ParseFn[V]
+	validators []ValidateFn[V]
+}
+
+// NewMap returns a new Map that converts keys and values with the specified parse functions
+// and checks values with the specified validators.
+func NewMap[K comparable, V any](parseKey ParseFn[K], parseValue ParseFn[V], validators ...ValidateFn[V]) *Map[K, V] {
+	return &Map[K, V]{
+		parseKey:   parseKey,
+		parseValue: parseValue,
+		validators: validators,
+	}
+}
+
+// String representation of the map of flag values, sorted by key.
+func (m *Map[K, V]) String() string {
+	if m == nil {
+		return "[]"
+	}
+	pairs := make([]string, 0, len(m.Values))
+	for k, v := range m.Values {
+		pairs = append(pairs, fmt.Sprintf("%v:%v", k, v))
+	}
+	sort.Strings(pairs)
+	return "[" + strings.Join(pairs, ",") + "]"
+}
+
+// Set a key/value item into the map.
+func (m *Map[K, V]) Set(value string) error {
+	if m.parseKey == nil || m.parseValue == nil {
+		return errNoParseFn
+	}
+	keyText, valueText, err := splitPair(value, ':')
+	if err != nil {
+		return err
+	}
+	key, err := parseItem(keyText, m.parseKey, nil)
+	if err != nil {
+		return fmt.Errorf("key: %w", err)
+	}
+	val, err := parseItem(valueText, m.parseValue, m.validators)
+	if err != nil {
+		return fmt.Errorf("value: %w", err)
+	}
+	if m.Values == nil {
+		m.Values = make(map[K]V)
+	}
+	m.Values[key] = val
+	return nil
+}
+
+// Get returns the accumulated values as a map[K]V.
+// Implements the flag.Getter interface.
+func (m *Map[K, V]) Get() interface{} {
+	return m.Values
+}
+
+func (m *Map[K, V]) reset() {
+	m.Values = nil
+}
@@ -0,0 +1,33 @@
+package flag
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMap(t *testing.T) {
+	timeouts := NewMap(ParseString, time.ParseDuration, Range(time.Second, time.Minute))
+	flags := newFlagSet()
+	flags.Var(timeouts, "timeout", "Some description for this param.")
+	require.NoError(t, flags.Parse([]string{"-timeout", "read:5s", "-timeout", "write : 10s"}))
+	assert.Equal(t, map[string]time.Duration{"read": 5 * time.Second, "write": 10 * time.Second}, timeouts.Values)
+	assert.Equal(t, timeouts.Values, timeouts.Get())
+	assert.Equal(t, "[read:5s,write:10s]", timeouts.String())
+
+	assert.ErrorIs(t, timeouts.Set("read"), errNoColon)
+	assert.ErrorContains(t, timeouts.Set("read:never"), "value: parse")
+	assert.ErrorContains(t, timeouts.Set("read:1h"), "value: 1h0m0s not in range 1s to 1m0s")
+	assert.ErrorIs(t, (&Map[string, string]{}).Set("a:b"), errNoParseFn)
+}
+
+func TestMap_intKeys(t *testing.T) {
+	ports := NewMap(strconv.Atoi, ParseString)
+	require.NoError(t, ports.Set("80:http"))
+	require.NoError(t, ports.Set("443:https"))
+	assert.Equal(t, map[int]string{80: "http", 443: "https"}, ports.Values)
+	assert.ErrorContains(t, ports.Set("http:80"), `key: parse "http": invalid syntax`)
+}
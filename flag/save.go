@@ -0,0 +1,178 @@
+package flag
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// SaveSettings writes flag values from the specified flag.FlagSet to a settings file
+// that can be read by LoadSettings.
+// The type of the settings file is determined by its extension as for LoadSettings.
+// If all is true every flag is written, otherwise only flags that have been set
+// on the command line or that have values different from their defaults.
+// Note that LoadSettings replaces flag defaults so values from settings files
+// are only written when all is true.
+// Flags named in exclude (e.g. the flag that requested the file be written) are never written.
+// Flag names are written in dotted form (e.g. 'server.port') in all formats.
+// Flags with multiple values (e.g. StringArray or StringMap) are written as arrays
+// or as repeated keys for CFG and INI files.
+// CFG and INI files include the usage string of each flag as a comment
+// and values are quoted where necessary.
+func SaveSettings(flagSet *flag.FlagSet, file string, all bool, exclude ...string) error {
+	flags := settingsFlags(flagSet, all, exclude)
+	data, err := marshalSettings(filepath.Ext(file), flags)
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(file, data, 0644); err != nil {
+		return fmt.Errorf("write file '%s': %w", file, err)
+	}
+	return nil
+}
+
+// settingsFlags returns the flags to be saved in lexicographical order.
+func settingsFlags(flagSet *flag.FlagSet, all bool, exclude []string) []*flag.Flag {
+	skip := make(map[string]bool, len(exclude))
+	for _, name := range exclude {
+		skip[name] = true
+	}
+	set := make(map[string]bool)
+	flagSet.Visit(func(flg *flag.Flag) {
+		set[flg.Name] = true
+	})
+	flags := make([]*flag.Flag, 0)
+	flagSet.VisitAll(func(flg *flag.Flag) {
+		if !skip[flg.Name] && (all || set[flg.Name] || flg.Value.String() != flg.DefValue) {
+			flags = append(flags, flg)
+		}
+	})
+	return flags
+}
+
+// settingsValuer is implemented by flag values that quote their setting values
+// so that they are not split differently when they are loaded.
+type settingsValuer interface {
+	settingValues() []string
+}
+
+// settingValues returns the setting values for a flag quoted as necessary for flag.Value.Set.
+func settingValues(flg *flag.Flag) []string {
+	if valuer, ok := flg.Value.(settingsValuer); ok {
+		return valuer.settingValues()
+	}
+	return itemValues(flg)
+}
+
+// itemValues returns the unquoted setting values for a flag.
+// Flags with slice or map values (directly or via flag.Getter) have one setting value per item,
+// with map items formatted as 'key:value' and sorted.
+func itemValues(flg *flag.Flag) []string {
+	var value interface{} = flg.Value
+	if getter, ok := flg.Value.(flag.Getter); ok {
+		value = getter.Get()
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Slice:
+		values := make([]string, rv.Len())
+		for i := range values {
+			values[i] = fmt.Sprint(rv.Index(i).Interface())
+		}
+		return values
+	case reflect.Map:
+		values := make([]string, 0, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			values = append(values, fmt.Sprintf("%v:%v", iter.Key().Interface(), iter.Value().Interface()))
+		}
+		sort.Strings(values)
+		return values
+	default:
+		return []string{flg.Value.String()}
+	}
+}
+
+// isMultiple returns true if the flag may have multiple setting values.
+func isMultiple(flg *flag.Flag) bool {
+	var value interface{} = flg.Value
+	if getter, ok := flg.Value.(flag.Getter); ok {
+		value = getter.Get()
+	}
+	kind := reflect.Indirect(reflect.ValueOf(value)).Kind()
+	return kind == reflect.Slice || kind == reflect.Map
+}
+
+// marshalSettings converts flag values into the contents of a settings file.
+// The file type is specified by its extension.
+func marshalSettings(ext string, flags []*flag.Flag) ([]byte, error) {
+	switch ext = strings.ToLower(ext); ext {
+	case ".json", ".yaml", ".yml", ".toml":
+		tree := make(map[string]interface{}, len(flags))
+		for _, flg := range flags {
+			if _, splits := splitSeparator(flg.Value); splits {
+				// Array elements are loaded as single items.
+				tree[flg.Name] = itemValues(flg)
+			} else if isMultiple(flg) {
+				tree[flg.Name] = settingValues(flg)
+			} else {
+				tree[flg.Name] = flg.Value.String()
+			}
+		}
+		return marshalTree(ext, tree)
+	case ".ini", ".cfg":
+		var buf bytes.Buffer
+		for i, flg := range flags {
+			if i > 0 {
+				buf.WriteString("\n")
+			}
+			if flg.Usage != "" {
+				for _, line := range strings.Split(flg.Usage, "\n") {
+					_, _ = fmt.Fprintf(&buf, "# %s\n", line)
+				}
+			}
+			for _, value := range settingValues(flg) {
+				_, _ = fmt.Fprintf(&buf, "%s = %s\n", quoteConfig(flg.Name, true), quoteConfig(value, false))
+			}
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unknown settings file extension '%s'", ext)
+	}
+}
+
+// marshalTree converts a map of settings into JSON, YAML, or TOML.
+func marshalTree(ext string, tree map[string]interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	switch ext {
+	case ".json":
+		encoder := json.NewEncoder(&buf)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(tree); err != nil {
+			return nil, fmt.Errorf("marshal JSON settings: %w", err)
+		}
+	case ".yaml", ".yml":
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(tree); err != nil {
+			return nil, fmt.Errorf("marshal YAML settings: %w", err)
+		}
+	case ".toml":
+		if err := toml.NewEncoder(&buf).Encode(tree); err != nil {
+			return nil, fmt.Errorf("marshal TOML settings: %w", err)
+		}
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,139 @@
+package flag
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type saveFlags struct {
+	text      string
+	whole     int
+	float     float64
+	verbose   bool
+	port      int
+	tags      StringArray
+	labels    StringMap
+	delays    DurationArray
+	counts    *Slice[int]
+	level     *Enum
+	writeFile string
+}
+
+func (sf *saveFlags) flagSet() *flag.FlagSet {
+	flagSet := makeFlagSet(&sf.text, &sf.whole, &sf.float)
+	flagSet.BoolVar(&sf.verbose, "verbose", false, "Verbose")
+	flagSet.IntVar(&sf.port, "server.port", 80, "Server port")
+	sf.tags, sf.labels, sf.delays = nil, nil, nil
+	flagSet.Var(&sf.tags, "tag", "Tags")
+	flagSet.Var(&sf.labels, "label", "Labels")
+	flagSet.Var(&sf.delays, "delay", "Delays")
+	sf.counts = NewSlice(strconv.Atoi)
+	flagSet.Var(sf.counts, "count", "Counts")
+	sf.level = NewEnum("info", "debug", "info", "warn")
+	flagSet.Var(sf.level, "level", "Log level\nOne of debug, info, or warn")
+	flagSet.StringVar(&sf.writeFile, "write-config", "", "Write settings file")
+	return flagSet
+}
+
+func TestSaveSettings_roundTrip(t *testing.T) {
+	dir := t.TempDir()
+	for _, ext := range []string{"json", "yaml", "toml", "ini", "cfg"} {
+		t.Run(ext, func(t *testing.T) {
+			file := filepath.Join(dir, "saved."+ext)
+			var saved saveFlags
+			flagSet := saved.flagSet()
+			require.NoError(t, flagSet.Parse([]string{
+				"-text", " Round Trip = \"quoted\" ", "-verbose", "-server.port", "8080",
+				"-tag", "alpha", "-tag", `bravo,"charlie, delta"`, "-label", "x:1", "-label", `y:"http://host"`,
+				"-delay", "1s,1m30s", "-count", "1,2,3", "-level", "warn", "-write-config", file,
+			}))
+			require.NoError(t, SaveSettings(flagSet, file, false, "write-config"))
+
+			var loaded saveFlags
+			flagSet = loaded.flagSet()
+			_, err := LoadSettingsFromArgs(flagSet, []string{"@" + file})
+			require.NoError(t, err)
+			assert.Equal(t, " Round Trip = \"quoted\" ", loaded.text)
+			assert.Equal(t, 13, loaded.whole)
+			assert.Equal(t, 1.61803, loaded.float)
+			assert.True(t, loaded.verbose)
+			assert.Equal(t, 8080, loaded.port)
+			assert.Equal(t, StringArray{"alpha", "bravo", "charlie, delta"}, loaded.tags)
+			assert.Equal(t, StringMap{"x": "1", "y": "http://host"}, loaded.labels)
+			assert.Equal(t, DurationArray{time.Second, 90 * time.Second}, loaded.delays)
+			assert.Equal(t, []int{1, 2, 3}, loaded.counts.Values)
+			assert.Equal(t, "warn", loaded.level.Value)
+			assert.Empty(t, loaded.writeFile)
+		})
+	}
+}
+
+func TestSaveSettings_all(t *testing.T) {
+	var sf saveFlags
+	flagSet := sf.flagSet()
+	require.NoError(t, flagSet.Parse([]string{"-whole", "42"}))
+	file := filepath.Join(t.TempDir(), "all.cfg")
+	require.NoError(t, SaveSettings(flagSet, file, true, "write-config", "count", "delay", "label", "tag"))
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, ""+
+		"# Floating point\n"+
+		"float = 1.61803\n"+
+		"\n"+
+		"# Log level\n"+
+		"# One of debug, info, or warn\n"+
+		"level = info\n"+
+		"\n"+
+		"# Server port\n"+
+		"server.port = 80\n"+
+		"\n"+
+		"# Text String\n"+
+		"text = Lorem Ipsum\n"+
+		"\n"+
+		"# Verbose\n"+
+		"verbose = false\n"+
+		"\n"+
+		"# Integer\n"+
+		"whole = 42\n",
+		string(data))
+
+	file = filepath.Join(t.TempDir(), "changed.json")
+	require.NoError(t, SaveSettings(flagSet, file, false))
+	data, err = os.ReadFile(file)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"whole": "42"}`, string(data))
+}
+
+func TestSaveSettings_errors(t *testing.T) {
+	var sf saveFlags
+	flagSet := sf.flagSet()
+	assert.ErrorContains(t, SaveSettings(flagSet, filepath.Join(t.TempDir(), "bad.xml"), true),
+		"unknown settings file extension '.xml'")
+	assert.ErrorContains(t, SaveSettings(flagSet, filepath.Join(t.TempDir(), "none", "bad.cfg"), true),
+		"write file")
+}
+
+func ExampleSaveSettings() {
+	var (
+		text  string
+		whole int
+		float float64
+	)
+	flagSet := makeFlagSet(&text, &whole, &float)
+	_ = flagSet.Parse([]string{"-text", "Saved"})
+	file := filepath.Join(os.TempDir(), "example-settings.cfg")
+	defer func() { _ = os.Remove(file) }()
+	_ = SaveSettings(flagSet, file, false)
+	data, _ := os.ReadFile(file)
+	fmt.Print(string(data))
+	// Output: # Text String
+	// text = Saved
+}
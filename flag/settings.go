@@ -0,0 +1,118 @@
+package flag
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// unmarshalSettings converts the contents of a settings file into flag values by flag name.
+// The file type is specified by its extension.
+// The names of settings from arrays in JSON, YAML, and TOML files are also returned
+// as each of their values is a single item.
+func unmarshalSettings(ext string, data []byte) (map[string][]string, map[string]bool, error) {
+	var tree map[string]interface{}
+	switch strings.ToLower(ext) {
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&tree); err != nil {
+			return nil, nil, fmt.Errorf("unmarshal JSON settings: %w", err)
+		}
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &tree); err != nil {
+			return nil, nil, fmt.Errorf("unmarshal YAML settings: %w", err)
+		}
+	case ".toml":
+		if err := toml.Unmarshal(data, &tree); err != nil {
+			return nil, nil, fmt.Errorf("unmarshal TOML settings: %w", err)
+		}
+	case ".ini", ".cfg":
+		settings, err := parseConfig(string(data))
+		if err != nil {
+			return nil, nil, fmt.Errorf("unmarshal %s settings: %w", strings.ToUpper(ext[1:]), err)
+		}
+		return settings, nil, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown settings file extension '%s'", ext)
+	}
+
+	settings := make(map[string][]string)
+	arrays := make(map[string]bool)
+	if err := flatten("", tree, settings, arrays); err != nil {
+		return nil, nil, fmt.Errorf("flatten settings: %w", err)
+	}
+	return settings, arrays, nil
+}
+
+// flatten converts a tree of settings into flag values by flag name.
+// Nested maps are flattened into dotted names and arrays into multiple values.
+// The names of array settings are recorded as their values are separate items.
+func flatten(prefix string, tree map[string]interface{}, settings map[string][]string, arrays map[string]bool) error {
+	// Sort keys so that errors are deterministic.
+	keys := make([]string, 0, len(tree))
+	for key := range tree {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		name := prefix + key
+		switch value := tree[key].(type) {
+		case map[string]interface{}:
+			if err := flatten(name+".", value, settings, arrays); err != nil {
+				return err
+			}
+		case []interface{}:
+			values := make([]string, 0, len(value))
+			for _, item := range value {
+				text, err := scalar(item)
+				if err != nil {
+					return fmt.Errorf("array '%s': %w", name, err)
+				}
+				values = append(values, text)
+			}
+			settings[name] = values
+			arrays[name] = true
+		default:
+			text, err := scalar(value)
+			if err != nil {
+				return fmt.Errorf("setting '%s': %w", name, err)
+			}
+			settings[name] = []string{text}
+		}
+	}
+	return nil
+}
+
+// scalar converts a scalar setting value into a flag value string.
+func scalar(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case json.Number:
+		return v.String(), nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	default:
+		return "", fmt.Errorf("unsupported value type %T", value)
+	}
+}
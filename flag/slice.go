@@ -0,0 +1,98 @@
+package flag
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// Slice defines a flag that can be invoked multiple times with typed values accumulated in a slice.
+// Comma-separated values may be combined in a single flag argument as with StringArray.
+// Each value is converted by a parse function and checked by any validators.
+// Construct Slice objects using NewSlice().
+type Slice[T any] struct {
+	// Values accumulated from flag arguments.
+	Values     []T
+	parse      ParseFn[T]
+	validators []ValidateFn[T]
+}
+
+var errNoParseFn = errors.New("no parse function")
+
+// NewSlice returns a new Slice that converts each value with the specified parse function
+// and checks it with the specified validators.
+func NewSlice[T any](parse ParseFn[T], validators ...ValidateFn[T]) *Slice[T] {
+	return &Slice[T]{
+		parse:      parse,
+		validators: validators,
+	}
+}
+
+// String representation of the array of flag values.
+func (s *Slice[T]) String() string {
+	if s == nil {
+		return "[]"
+	}
+	return joinValues(s.Values)
+}
+
+// Set a value(s) into the array.
+// No values are added if any value fails to parse or validate.
+func (s *Slice[T]) Set(value string) error {
+	if s.parse == nil {
+		return errNoParseFn
+	}
+	parsed, err := parseList(value, s.parse, s.validators)
+	if err != nil {
+		return err
+	}
+	s.Values = append(s.Values, parsed...)
+	return nil
+}
+
+// Get returns the accumulated values as a []T.
+// Implements the flag.Getter interface.
+func (s *Slice[T]) Get() interface{} {
+	return s.Values
+}
+
+func (s *Slice[T]) reset() {
+	s.Values = nil
+}
+
+func (s *Slice[T]) itemSeparator() rune {
+	return ','
+}
+
+// parseList splits a comma-separated flag argument, parses and validates the values.
+func parseList[T any](value string, parse ParseFn[T], validators []ValidateFn[T]) ([]T, error) {
+	items := splitItems(value, ',', 0)
+	result := make([]T, 0, len(items))
+	for _, item := range items {
+		parsed, err := parseItem(item, parse, validators)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, parsed)
+	}
+	return result, nil
+}
+
+// parseItem parses and validates a single value.
+func parseItem[T any](item string, parse ParseFn[T], validators []ValidateFn[T]) (T, error) {
+	parsed, err := parse(item)
+	if err != nil {
+		var zero T
+		var numErr *strconv.NumError
+		if errors.As(err, &numErr) {
+			// Avoid repeating the function name and value.
+			err = numErr.Err
+		}
+		return zero, fmt.Errorf("parse %q: %w", item, err)
+	}
+	if err = validate(parsed, validators); err != nil {
+		var zero T
+		return zero, err
+	}
+	return parsed, nil
+}
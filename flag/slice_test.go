@@ -0,0 +1,37 @@
+package flag
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlice(t *testing.T) {
+	floats := NewSlice(func(s string) (float64, error) {
+		return strconv.ParseFloat(s, 64)
+	}, Range(0.0, 1.0))
+	flags := newFlagSet()
+	flags.Var(floats, "float", "Some description for this param.")
+	require.NoError(t, flags.Parse([]string{"-float", "0.5,1", "-float", "0"}))
+	assert.Equal(t, []float64{0.5, 1, 0}, floats.Values)
+	assert.Equal(t, []float64{0.5, 1, 0}, floats.Get())
+	assert.Equal(t, "[0.5,1,0]", floats.String())
+
+	err := flags.Parse([]string{"-float", "2"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "2 not in range 0 to 1")
+	assert.ErrorIs(t, (&Slice[int]{}).Set("1"), errNoParseFn)
+}
+
+func TestSlice_strings(t *testing.T) {
+	names := NewSlice(ParseString, Regex("^[a-z]+$"))
+	flags := newFlagSet()
+	flags.Var(names, "name", "Some description for this param.")
+	require.NoError(t, flags.Parse([]string{"-name", "alpha, bravo"}))
+	assert.Equal(t, []string{"alpha", "bravo"}, names.Values)
+	err := flags.Parse([]string{"-name", "Charlie"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"Charlie" does not match "^[a-z]+$"`)
+}
@@ -0,0 +1,139 @@
+package flag
+
+import (
+	"flag"
+	"strings"
+	"unicode"
+)
+
+// splitItems splits a flag argument into items at the separator, removing white space around each item.
+// If limit is greater than zero at most limit items are returned,
+// the last containing the rest of the argument.
+// A separator of zero returns a single item.
+//
+// An item may be quoted to include separators or surrounding white space.
+// Within double quotes a backslash escapes the next character.
+// Single quotes contain literal text.
+// A leading quote only starts a quoted item if the matching quote ends the item,
+// otherwise it is taken literally (e.g. '90s).
+// Outside of quotes a backslash escapes a separator or a quote
+// and is otherwise taken literally (e.g. in Windows paths such as \\server\share).
+func splitItems(value string, separator rune, limit int) []string {
+	runes := []rune(value)
+	items := make([]string, 0, 1)
+	for start := 0; ; {
+		last := separator == 0 || limit > 0 && len(items) == limit-1
+		item, next := scanItem(runes, start, separator, last)
+		items = append(items, item)
+		if next >= len(runes) {
+			return items
+		}
+		// Skip the separator.
+		start = next + 1
+	}
+}
+
+// scanItem returns the item starting at the specified index and
+// the index of the separator following it (or the end of the argument).
+// The separator is ignored if this is the last item.
+func scanItem(runes []rune, start int, separator rune, last bool) (string, int) {
+	i := start
+	for i < len(runes) && unicode.IsSpace(runes[i]) {
+		i++
+	}
+	if item, next, ok := scanQuoted(runes, i, separator, last); ok {
+		return item, next
+	}
+	var item strings.Builder
+	for ; i < len(runes); i++ {
+		r := runes[i]
+		if r == separator && !last {
+			break
+		} else if r == '\\' && i+1 < len(runes) && isEscapable(runes[i+1], separator) {
+			i++
+			r = runes[i]
+		}
+		item.WriteRune(r)
+	}
+	return strings.TrimRightFunc(item.String(), unicode.IsSpace), i
+}
+
+// scanQuoted returns the quoted item starting at the specified index and
+// the index of the separator following it (or the end of the argument).
+// Returns false if there is no quote at the index or the matching quote
+// is not followed by the separator or the end of the argument.
+func scanQuoted(runes []rune, i int, separator rune, last bool) (string, int, bool) {
+	if i >= len(runes) || runes[i] != '"' && runes[i] != '\'' {
+		return "", 0, false
+	}
+	quote := runes[i]
+	var item strings.Builder
+	for i++; ; i++ {
+		if i >= len(runes) {
+			return "", 0, false
+		}
+		r := runes[i]
+		if r == quote {
+			i++
+			break
+		} else if r == '\\' && quote == '"' && i+1 < len(runes) {
+			i++
+			r = runes[i]
+		}
+		item.WriteRune(r)
+	}
+	for i < len(runes) && unicode.IsSpace(runes[i]) {
+		i++
+	}
+	if i < len(runes) && (last || runes[i] != separator) {
+		return "", 0, false
+	}
+	return item.String(), i, true
+}
+
+// isEscapable returns true if the character may be escaped by a backslash outside of quotes.
+func isEscapable(r rune, separator rune) bool {
+	return r == separator || r == '"' || r == '\''
+}
+
+// quoteItem returns the item quoted if necessary so that splitItems returns it unchanged.
+func quoteItem(item string, separator rune) string {
+	if item == "" || !needsQuotes(item, separator) {
+		return item
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(item) + `"`
+}
+
+// needsQuotes returns true if splitItems would not return the item unchanged.
+func needsQuotes(item string, separator rune) bool {
+	if strings.TrimSpace(item) != item ||
+		separator != 0 && strings.ContainsRune(item, separator) ||
+		strings.HasPrefix(item, `"`) || strings.HasPrefix(item, `'`) ||
+		strings.HasSuffix(item, `\`) {
+		return true
+	}
+	runes := []rune(item)
+	for i := 0; i+1 < len(runes); i++ {
+		if runes[i] == '\\' && isEscapable(runes[i+1], separator) {
+			return true
+		}
+	}
+	return false
+}
+
+// itemSplitter is implemented by flag values that split each flag argument into items.
+type itemSplitter interface {
+	itemSeparator() rune
+}
+
+// splitSeparator returns the separator at which the flag value splits each flag argument into items
+// and true if the flag value splits its arguments.
+func splitSeparator(value flag.Value) (rune, bool) {
+	if dv, ok := value.(*defaultValue); ok {
+		value = dv.Value
+	}
+	if splitter, ok := value.(itemSplitter); ok {
+		return splitter.itemSeparator(), true
+	}
+	return 0, false
+}
@@ -0,0 +1,55 @@
+package flag
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitItems(t *testing.T) {
+	for _, test := range []struct {
+		value     string
+		separator rune
+		limit     int
+		expected  []string
+	}{
+		{"", ',', 0, []string{""}},
+		{"one", ',', 0, []string{"one"}},
+		{" one , two ,three ", ',', 0, []string{"one", "two", "three"}},
+		{`"one, two" , three`, ',', 0, []string{"one, two", "three"}},
+		{`' spaced ',"esc\"aped\\"`, ',', 0, []string{" spaced ", `esc"aped\`}},
+		{`a\,b,c\"d,e\'f`, ',', 0, []string{"a,b", `c"d`, "e'f"}},
+		{`C:\dir\file,it's`, ',', 0, []string{`C:\dir\file`, "it's"}},
+		{"a;b,c", ';', 0, []string{"a", "b,c"}},
+		{"a,b", 0, 0, []string{"a,b"}},
+		{"url:http://host:80", ':', 2, []string{"url", "http://host:80"}},
+		{`"a:b":"c"`, ':', 2, []string{"a:b", "c"}},
+		{`a\:b:c\:d`, ':', 2, []string{"a:b", "c:d"}},
+		{"a,", ',', 0, []string{"a", ""}},
+		{`\\server\share,\\host\c$\dir\\`, ',', 0, []string{`\\server\share`, `\\host\c$\dir\\`}},
+		{`"\\server\\share"`, ',', 0, []string{`\server\share`}},
+		{"'90s,'80s", ',', 0, []string{"'90s", "'80s"}},
+		{"'90s and '80s", ',', 0, []string{"'90s and '80s"}},
+		{`"a"b,"c`, ',', 0, []string{`"a"b`, `"c`}},
+		{`k:"a":b`, ':', 2, []string{"k", `"a":b`}},
+	} {
+		assert.Equal(t, test.expected, splitItems(test.value, test.separator, test.limit), test.value)
+	}
+}
+
+func TestQuoteItem(t *testing.T) {
+	for _, item := range []string{
+		"", "plain", "a,b", " spaced ", `back\slash`, `"quoted"`, "it's", "'90s", "a:b",
+		`\\server\share`, `trailing\`, `esc\,aped`, `esc\"aped`,
+	} {
+		for _, separator := range []rune{',', ':', 0} {
+			assert.Equal(t, []string{item}, splitItems(quoteItem(item, separator), separator, 0), item)
+		}
+	}
+	assert.Equal(t, "plain", quoteItem("plain", ','))
+	assert.Equal(t, "a:b", quoteItem("a:b", ','))
+	assert.Equal(t, `"a,b"`, quoteItem("a,b", ','))
+	assert.Equal(t, `"\"q\" \\"`, quoteItem(`"q" \`, ','))
+	assert.Equal(t, `\\server\share`, quoteItem(`\\server\share`, ','))
+	assert.Equal(t, "it's", quoteItem("it's", ','))
+}
@@ -2,35 +2,194 @@ package flag
 
 import (
 	"errors"
-	"regexp"
+	"flag"
+	"fmt"
+	"sort"
 	"strings"
 )
 
 // StringMap defines a flag that can be invoked multiple times with values accumulated in a map.
-// Each flag value is broken at its embedded colon to create a key/value pair.
+// Each flag value is broken at its first embedded colon to create a key/value pair.
+// Extra space(s) around the colon are removed.
+// Keys containing colons may be quoted (e.g. '"a:b":c') or the colons escaped (e.g. 'a\:b:c').
+// Later values for the same key overwrite earlier ones.
+// Use NewStringMap to specify a different separator or to reject duplicate keys
+// and StringMultiMap to accumulate all values for each key.
 type StringMap map[string]string
 
-// String representation of the array of flag values.
+// String representation of the map of flag values, sorted by key.
 func (i *StringMap) String() string {
 	pairs := make([]string, 0, len(*i))
 	for k, v := range *i {
 		pairs = append(pairs, k+":"+v)
 	}
+	sort.Strings(pairs)
 	return "[" + strings.Join(pairs, ",") + "]"
 }
 
-var colonSplitter = regexp.MustCompile("\\s*:\\s*")
-var errNoColon = errors.New("no colon")
+var (
+	errNoColon      = errors.New("no colon")
+	errDuplicateKey = errors.New("duplicate key")
+)
 
 // Set a key/value item into the array.
 func (i *StringMap) Set(value string) error {
-	stuff := colonSplitter.Split(value, 2)
-	if len(stuff) < 2 {
-		return errNoColon
+	return NewStringMap(i, ':', DuplicatesOverwrite).Set(value)
+}
+
+// Get returns the accumulated values as a map[string]string.
+// Implements the flag.Getter interface.
+func (i *StringMap) Get() interface{} {
+	return map[string]string(*i)
+}
+
+func (i *StringMap) reset() {
+	*i = nil
+}
+
+func (i *StringMap) settingValues() []string {
+	return NewStringMap(i, ':', DuplicatesOverwrite).(*stringMapValue).settingValues()
+}
+
+// splitPair splits a flag argument into a key and a value at the separator.
+func splitPair(value string, separator rune) (string, string, error) {
+	items := splitItems(value, separator, 2)
+	if len(items) < 2 {
+		if separator == ':' {
+			return "", "", errNoColon
+		}
+		return "", "", fmt.Errorf("no separator '%c'", separator)
+	}
+	return items[0], items[1], nil
+}
+
+// pairValue returns a key/value pair quoted as necessary for splitPair.
+func pairValue(key, value string, separator rune) string {
+	return quoteItem(key, separator) + string(separator) + quoteItem(value, 0)
+}
+
+//////////////////////////////////////////////////////////////////////////
+
+// Duplicates specifies how a StringMap flag value created by NewStringMap handles duplicate keys.
+type Duplicates uint8
+
+const (
+	// DuplicatesOverwrite replaces the previous value for a key.
+	DuplicatesOverwrite Duplicates = iota
+	// DuplicatesError returns an error for a key that already has a value.
+	DuplicatesError
+)
+
+// NewStringMap returns a flag value that accumulates values into the specified StringMap
+// breaking flag arguments at the specified separator instead of a colon
+// and handling duplicate keys as specified.
+//
+//	flagSet.Var(flag.NewStringMap(&env, '=', flag.DuplicatesError), "env", "Environment variables")
+func NewStringMap(values *StringMap, separator rune, duplicates Duplicates) flag.Getter {
+	return &stringMapValue{values: values, separator: separator, duplicates: duplicates}
+}
+
+type stringMapValue struct {
+	values     *StringMap
+	separator  rune
+	duplicates Duplicates
+}
+
+func (smv *stringMapValue) String() string {
+	if smv.values == nil {
+		return "[]"
+	}
+	return smv.values.String()
+}
+
+func (smv *stringMapValue) Set(value string) error {
+	key, val, err := splitPair(value, smv.separator)
+	if err != nil {
+		return err
+	}
+	if *smv.values == nil {
+		*smv.values = make(StringMap)
+	} else if _, found := (*smv.values)[key]; found && smv.duplicates == DuplicatesError {
+		return fmt.Errorf("%w '%s'", errDuplicateKey, key)
+	}
+	(*smv.values)[key] = val
+	return nil
+}
+
+func (smv *stringMapValue) Get() interface{} {
+	return smv.values.Get()
+}
+
+func (smv *stringMapValue) reset() {
+	smv.values.reset()
+}
+
+// settingValues returns the key/value pairs sorted and quoted as necessary for Set.
+func (smv *stringMapValue) settingValues() []string {
+	values := make([]string, 0, len(*smv.values))
+	for key, value := range *smv.values {
+		values = append(values, pairValue(key, value, smv.separator))
+	}
+	sort.Strings(values)
+	return values
+}
+
+//////////////////////////////////////////////////////////////////////////
+
+// StringMultiMap defines a flag that can be invoked multiple times with values accumulated in a map.
+// Flag values are broken into key/value pairs as for StringMap
+// but all values for each key are accumulated in an array.
+type StringMultiMap map[string][]string
+
+// String representation of the map of flag values, sorted by key.
+func (i *StringMultiMap) String() string {
+	keys := make([]string, 0, len(*i))
+	for key := range *i {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(*i))
+	for _, key := range keys {
+		pairs = append(pairs, key+":["+strings.Join((*i)[key], ",")+"]")
+	}
+	return "[" + strings.Join(pairs, ",") + "]"
+}
+
+// Set a key/value item into the map.
+func (i *StringMultiMap) Set(value string) error {
+	key, val, err := splitPair(value, ':')
+	if err != nil {
+		return err
 	}
 	if *i == nil {
-		*i = make(StringMap)
+		*i = make(StringMultiMap)
 	}
-	(*i)[stuff[0]] = stuff[1]
+	(*i)[key] = append((*i)[key], val)
 	return nil
 }
+
+// Get returns the accumulated values as a map[string][]string.
+// Implements the flag.Getter interface.
+func (i *StringMultiMap) Get() interface{} {
+	return map[string][]string(*i)
+}
+
+func (i *StringMultiMap) reset() {
+	*i = nil
+}
+
+// settingValues returns the key/value pairs sorted by key and quoted as necessary for Set.
+func (i *StringMultiMap) settingValues() []string {
+	keys := make([]string, 0, len(*i))
+	for key := range *i {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	values := make([]string, 0)
+	for _, key := range keys {
+		for _, value := range (*i)[key] {
+			values = append(values, pairValue(key, value, ':'))
+		}
+	}
+	return values
+}
@@ -39,3 +39,50 @@ func Example_mapStringFlags() {
 	fmt.Println(testFlags)
 	// Output: map[one:alpha two:bravo]
 }
+
+func TestStringMap_quoted(t *testing.T) {
+	var values StringMap
+	flagSet := newFlagSet()
+	flagSet.Var(&values, "value", "Values")
+	require.NoError(t, flagSet.Parse([]string{
+		"-value", "url : http://host:80", "-value", `"a:b":c`, "-value", `d\:e:" f "`, "-value", "url:http://other"}))
+	assert.Equal(t, StringMap{"url": "http://other", "a:b": "c", "d:e": " f "}, values)
+	assert.Equal(t, "[a:b:c,d:e: f ,url:http://other]", values.String())
+	assert.Equal(t, map[string]string(values), flagSet.Lookup("value").Value.(flag.Getter).Get())
+	assert.Equal(t, []string{`"a:b":c`, `"d:e":" f "`, "url:http://other"}, values.settingValues())
+	assert.ErrorIs(t, values.Set("no colon"), errNoColon)
+}
+
+func TestNewStringMap(t *testing.T) {
+	var values StringMap
+	flagSet := newFlagSet()
+	flagSet.Var(NewStringMap(&values, '=', DuplicatesError), "value", "Values")
+	require.NoError(t, flagSet.Parse([]string{"-value", "PATH=/bin:/usr/bin", "-value", "HOME = /home/me"}))
+	assert.Equal(t, StringMap{"PATH": "/bin:/usr/bin", "HOME": "/home/me"}, values)
+	assert.ErrorContains(t, flagSet.Parse([]string{"-value", "HOME=/root"}), "duplicate key 'HOME'")
+	assert.ErrorIs(t, flagSet.Lookup("value").Value.Set("PATH=/sbin"), errDuplicateKey)
+	assert.ErrorContains(t, flagSet.Parse([]string{"-value", "no-separator"}), "no separator '='")
+	assert.Equal(t, []string{"HOME=/home/me", "PATH=/bin:/usr/bin"},
+		flagSet.Lookup("value").Value.(settingsValuer).settingValues())
+}
+
+func TestStringMultiMap(t *testing.T) {
+	var values StringMultiMap
+	flagSet := newFlagSet()
+	flagSet.Var(&values, "value", "Values")
+	require.NoError(t, flagSet.Parse([]string{"-value", "b:2", "-value", "a:1", "-value", "b:http://x"}))
+	assert.Equal(t, StringMultiMap{"a": {"1"}, "b": {"2", "http://x"}}, values)
+	assert.Equal(t, "[a:[1],b:[2,http://x]]", values.String())
+	assert.Equal(t, map[string][]string(values), values.Get())
+	assert.Equal(t, []string{"a:1", "b:2", "b:http://x"}, values.settingValues())
+	assert.ErrorIs(t, values.Set("none"), errNoColon)
+}
+
+func ExampleStringMultiMap() {
+	var headers StringMultiMap
+	flagSet := flag.NewFlagSet("example", flag.ContinueOnError)
+	flagSet.Var(&headers, "header", "HTTP headers")
+	_ = flagSet.Parse([]string{"-header", "Accept:text/html", "-header", "Accept:application/json"})
+	fmt.Println(headers["Accept"])
+	// Output: [text/html application/json]
+}
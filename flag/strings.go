@@ -1,13 +1,16 @@
 package flag
 
 import (
-	"regexp"
+	"flag"
 	"strings"
 )
 
 // StringArray defines a flag that can be invoked multiple times with values accumulated in an array.
 // Comma-separated values may be combined in a single flag argument to be separated into the array.
 // Extra space(s) around the commas are removed.
+// Values containing commas or surrounding spaces may be quoted (e.g. '"a, b",c')
+// or commas may be escaped with a backslash (e.g. 'a\,b').
+// Use NewStringArray to specify a different separator.
 type StringArray []string
 
 // String representation of the array of flag values.
@@ -15,10 +18,74 @@ func (i *StringArray) String() string {
 	return "[" + strings.Join(*i, ",") + "]"
 }
 
-var commaSplitter = regexp.MustCompile("\\s*,\\s*")
-
 // Set a value(s) into the array.
 func (i *StringArray) Set(value string) error {
-	*i = append(*i, commaSplitter.Split(value, -1)...)
+	return NewStringArray(i, ',').Set(value)
+}
+
+// Get returns the accumulated values as a []string.
+// Implements the flag.Getter interface.
+func (i *StringArray) Get() interface{} {
+	return []string(*i)
+}
+
+func (i *StringArray) reset() {
+	*i = nil
+}
+
+func (i *StringArray) itemSeparator() rune {
+	return ','
+}
+
+func (i *StringArray) settingValues() []string {
+	return NewStringArray(i, ',').(*stringArrayValue).settingValues()
+}
+
+//////////////////////////////////////////////////////////////////////////
+
+// NewStringArray returns a flag value that accumulates values into the specified StringArray
+// splitting flag arguments at the specified separator instead of commas.
+// A separator of zero does not split flag arguments.
+//
+//	flagSet.Var(flag.NewStringArray(&paths, os.PathListSeparator), "path", "Search paths")
+func NewStringArray(values *StringArray, separator rune) flag.Getter {
+	return &stringArrayValue{values: values, separator: separator}
+}
+
+type stringArrayValue struct {
+	values    *StringArray
+	separator rune
+}
+
+func (sav *stringArrayValue) String() string {
+	if sav.values == nil {
+		return "[]"
+	}
+	return sav.values.String()
+}
+
+func (sav *stringArrayValue) Set(value string) error {
+	*sav.values = append(*sav.values, splitItems(value, sav.separator, 0)...)
 	return nil
 }
+
+func (sav *stringArrayValue) Get() interface{} {
+	return sav.values.Get()
+}
+
+func (sav *stringArrayValue) reset() {
+	sav.values.reset()
+}
+
+func (sav *stringArrayValue) itemSeparator() rune {
+	return sav.separator
+}
+
+// settingValues returns the values quoted as necessary for Set.
+func (sav *stringArrayValue) settingValues() []string {
+	values := make([]string, len(*sav.values))
+	for i, value := range *sav.values {
+		values[i] = quoteItem(value, sav.separator)
+	}
+	return values
+}
@@ -51,3 +51,39 @@ func Example_stringArrayFlags() {
 	fmt.Println(testFlags)
 	// Output: [one two three four]
 }
+
+func TestStringArray_quoted(t *testing.T) {
+	var values StringArray
+	flagSet := newFlagSet()
+	flagSet.Var(&values, "value", "Values")
+	require.NoError(t, flagSet.Parse([]string{"-value", `"a, b",c\,d`, "-value", "http://host"}))
+	assert.Equal(t, StringArray{"a, b", "c,d", "http://host"}, values)
+	assert.Equal(t, []string{"a, b", "c,d", "http://host"}, flagSet.Lookup("value").Value.(flag.Getter).Get())
+	assert.Equal(t, []string{`"a, b"`, `"c,d"`, "http://host"}, values.settingValues())
+}
+
+func TestStringArray_literal(t *testing.T) {
+	var values StringArray
+	flagSet := newFlagSet()
+	flagSet.Var(&values, "value", "Values")
+	require.NoError(t, flagSet.Parse([]string{
+		"-value", `\\server\share`, "-value", `"unterminated`, "-value", "'90s,it's"}))
+	assert.Equal(t, StringArray{`\\server\share`, `"unterminated`, "'90s", "it's"}, values)
+	assert.Equal(t, []string{`\\server\share`, `"\"unterminated"`, `"'90s"`, "it's"}, values.settingValues())
+}
+
+func TestNewStringArray(t *testing.T) {
+	var values StringArray
+	flagSet := newFlagSet()
+	flagSet.Var(NewStringArray(&values, ';'), "value", "Values")
+	require.NoError(t, flagSet.Parse([]string{"-value", "a,b; c", "-value", `d\;e`}))
+	assert.Equal(t, StringArray{"a,b", "c", "d;e"}, values)
+	assert.Equal(t, "[a,b,c,d;e]", flagSet.Lookup("value").Value.String())
+	assert.Equal(t, []string{"a,b", "c", `"d;e"`}, flagSet.Lookup("value").Value.(settingsValuer).settingValues())
+
+	values = nil
+	flagSet = newFlagSet()
+	flagSet.Var(NewStringArray(&values, 0), "value", "Values")
+	require.NoError(t, flagSet.Parse([]string{"-value", "a,b;c"}))
+	assert.Equal(t, StringArray{"a,b;c"}, values)
+}
@@ -0,0 +1,70 @@
+package flag
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// ParseFn converts a flag argument into a value of a specific type.
+type ParseFn[T any] func(string) (T, error)
+
+// ValidateFn checks a flag value, returning an error if the value is invalid.
+type ValidateFn[T any] func(T) error
+
+// ParseString is a ParseFn that returns the flag argument unchanged.
+func ParseString(value string) (string, error) {
+	return value, nil
+}
+
+// Ordered is a constraint for types that support the < and > operators.
+type Ordered interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64 | ~string
+}
+
+// Range returns a ValidateFn that requires values between min and max inclusive.
+func Range[T Ordered](min, max T) ValidateFn[T] {
+	return func(value T) error {
+		if value < min || value > max {
+			return fmt.Errorf("%v not in range %v to %v", value, min, max)
+		}
+		return nil
+	}
+}
+
+// Regex returns a ValidateFn that requires values to match the specified regular expression.
+// Panics if the regular expression does not compile.
+func Regex(pattern string) ValidateFn[string] {
+	re := regexp.MustCompile(pattern)
+	return func(value string) error {
+		if !re.MatchString(value) {
+			return fmt.Errorf("%q does not match %q", value, pattern)
+		}
+		return nil
+	}
+}
+
+// FileExists returns a ValidateFn that requires values to be paths to existing files or directories.
+func FileExists() ValidateFn[string] {
+	return func(value string) error {
+		if _, err := os.Stat(value); err != nil {
+			if os.IsNotExist(err) {
+				return fmt.Errorf("file %q does not exist", value)
+			}
+			return fmt.Errorf("file %q: %w", value, err)
+		}
+		return nil
+	}
+}
+
+// validate applies validators to a value, returning the first error.
+func validate[T any](value T, validators []ValidateFn[T]) error {
+	for _, validator := range validators {
+		if err := validator(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package flag
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRange(t *testing.T) {
+	between := Range(1, 10)
+	assert.NoError(t, between(1))
+	assert.NoError(t, between(10))
+	assert.EqualError(t, between(0), "0 not in range 1 to 10")
+	assert.EqualError(t, between(11), "11 not in range 1 to 10")
+	assert.NoError(t, Range("b", "d")("c"))
+}
+
+func TestRegex(t *testing.T) {
+	hex := Regex("^[0-9a-f]+$")
+	assert.NoError(t, hex("c0ffee"))
+	assert.EqualError(t, hex("coffee"), `"coffee" does not match "^[0-9a-f]+$"`)
+	assert.Panics(t, func() {
+		Regex("[")
+	})
+}
+
+func TestFileExists(t *testing.T) {
+	exists := FileExists()
+	assert.NoError(t, exists("testdata/settings.json"))
+	assert.NoError(t, exists("testdata"))
+	assert.EqualError(t, exists("testdata/noSuchFile"), `file "testdata/noSuchFile" does not exist`)
+}
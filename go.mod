@@ -3,6 +3,7 @@ module github.com/madkins23/go-utils
 go 1.18
 
 require (
+	github.com/BurntSushi/toml v1.6.0
 	github.com/gertd/go-pluralize v0.2.1
 	github.com/mitchellh/go-homedir v1.1.0
 	github.com/rs/zerolog v1.31.0
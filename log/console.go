@@ -1,9 +1,13 @@
 package log
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/rs/zerolog"
@@ -13,17 +17,21 @@ import (
 // Console configures logging to output to console in a more readable format.
 // This will be slower logging than just using basic JSON output.
 // Output is sent to os.Stderr and timestamps are in local time.
+// Use SetLevel to change the log level.
 func Console() {
 	zerolog.TimestampFunc = func() time.Time {
 		return time.Now().Local()
 	}
-	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: "15:04:05"})
+	replaceLogger(log.Output(zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: "15:04:05"}))
 }
 
 // ConsoleOrFile is used to open a console log or a log file based on its fields.
 // The log to be used is the default zerolog object.
 // If a log file is opened it may be specified as JSON objects
 // or the more readable console log format.
+// A log file may be rotated by size and/or time (see RotatingFile)
+// or reopened on SIGHUP for rotation by an external program such as logrotate.
+// Additional outputs with their own formats and levels may be configured as Sinks.
 //
 // This struct has been configured with JSON and YAML struct tags.
 // This supports reading the contents from a JSON or YAML configuration file.
@@ -42,11 +50,26 @@ type ConsoleOrFile struct {
 	// otherwise console mode output with blank lines when application restarted.
 	AsJSON bool `json:"asJson" yaml:"asJson"`
 
+	// Minimum level of log messages (e.g. "info"), empty to leave the level unchanged.
+	Level string `json:"level" yaml:"level"`
+
+	// Level overrides for component loggers by component name (see ComponentLogger).
+	ComponentLevels map[string]string `json:"componentLevels" yaml:"componentLevels"`
+
+	// Rotation of logfile.
+	Rotation `yaml:",inline"`
+
+	// Additional outputs, e.g. to send warnings to a syslog server.
+	Sinks []Sink `json:"sinks" yaml:"sinks"`
+
 	// ConsoleWriter object available to change settings or nil if sending JSON to logfile.
 	writer *zerolog.ConsoleWriter
 
 	// Log file object if used or nil if Console is true.
-	logFile *os.File
+	logFile *RotatingFile
+
+	// Closer for any additional outputs.
+	sinks io.Closer
 }
 
 // AddFlagsToSet adds flags to the specified flag.FlagSet.
@@ -55,11 +78,25 @@ func (cof *ConsoleOrFile) AddFlagsToSet(flags *flag.FlagSet, logFile string) {
 	flags.BoolVar(&cof.Console, "console", false, "Log to the console instead of the specified log file")
 	flags.StringVar(&cof.LogFile, "logFile", logFile, "Log file path")
 	flags.BoolVar(&cof.AsJSON, "logJSON", false, "Log output to file as JSON objects")
+	flags.StringVar(&cof.Level, "logLevel", "", "Minimum log level (trace, debug, info, warn, error)")
+	flags.Var((*levelsFlag)(&cof.ComponentLevels), "logComponentLevel",
+		"Log level for a component as <component>=<level> (may be repeated)")
+	flags.IntVar(&cof.MaxSize, "logMaxSize", 0, "Rotate log file at this size in megabytes")
+	flags.StringVar(&cof.RotateEvery, "logRotateEvery", "", "Rotate log file at this interval (e.g. 24h)")
+	flags.IntVar(&cof.MaxBackups, "logMaxBackups", 0, "Maximum number of rotated log files to keep")
+	flags.StringVar(&cof.MaxAge, "logMaxAge", "", "Maximum age of rotated log files to keep (e.g. 720h)")
+	flags.BoolVar(&cof.Compress, "logCompress", false, "Compress rotated log files with gzip")
+	flags.BoolVar(&cof.ReopenOnHUP, "logReopen", false, "Reopen log file on SIGHUP")
 }
 
-// Setup opens the console log or log file as appropriate based on the object's fields.
+// Setup sets the log levels and opens the console log or log file
+// as appropriate based on the object's fields and any additional sinks.
 func (cof *ConsoleOrFile) Setup() error {
+	if err := applyLevels(levelState{Level: cof.Level, Components: cof.ComponentLevels}); err != nil {
+		return fmt.Errorf("log levels: %w", err)
+	}
 	var err error
+	var output io.Writer
 	zerolog.TimestampFunc = func() time.Time {
 		return time.Now().Local()
 	}
@@ -69,18 +106,28 @@ func (cof *ConsoleOrFile) Setup() error {
 			out = os.Stdout
 		}
 		cof.writer = &zerolog.ConsoleWriter{Out: out, TimeFormat: "15:04:05"}
-		log.Logger = log.Output(cof.writer)
-	} else if cof.logFile, err = os.OpenFile(cof.LogFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666); err != nil {
+		output = cof.writer
+	} else if cof.logFile, err = cof.Rotation.OpenFile(cof.LogFile); err != nil {
 		return fmt.Errorf("log file creation: %w", err)
 	} else if cof.AsJSON {
-		log.Logger = log.Output(cof.logFile)
+		output = cof.logFile
 	} else {
 		// Separate blocks of log statements for each run.
 		_, _ = fmt.Fprintln(cof.logFile)
 		// Use ConsoleWriter for readable text instead of JSON blocks.
 		cof.writer = &zerolog.ConsoleWriter{Out: cof.logFile, TimeFormat: "15:04:05", NoColor: true}
-		log.Logger = log.Output(cof.writer)
+		output = cof.writer
 	}
+	if len(cof.Sinks) > 0 {
+		sinks, closer, err := OpenSinks(cof.Sinks...)
+		if err != nil {
+			_ = cof.Close()
+			return fmt.Errorf("open log sinks: %w", err)
+		}
+		cof.sinks = closer
+		output = zerolog.MultiLevelWriter(output, sinks)
+	}
+	replaceLogger(log.Output(output))
 	return nil
 }
 
@@ -92,12 +139,18 @@ func (cof *ConsoleOrFile) Writer() *zerolog.ConsoleWriter {
 	return cof.writer
 }
 
-// Close any log file that may have been opened.
+// Close any log file or sinks that may have been opened.
 func (cof *ConsoleOrFile) Close() error {
+	var errs []error
 	if cof.logFile != nil {
-		return cof.logFile.Close()
+		errs = append(errs, cof.logFile.Close())
+		cof.logFile = nil
 	}
-	return nil
+	if cof.sinks != nil {
+		errs = append(errs, cof.sinks.Close())
+		cof.sinks = nil
+	}
+	return errors.Join(errs...)
 }
 
 // CloseForDefer closes any log file that may have been opened.
@@ -105,3 +158,32 @@ func (cof *ConsoleOrFile) Close() error {
 func (cof *ConsoleOrFile) CloseForDefer() {
 	_ = cof.Close()
 }
+
+//////////////////////////////////////////////////////////////////////////
+
+// levelsFlag is a flag.Value that accumulates '<component>=<level>' arguments into a map.
+type levelsFlag map[string]string
+
+func (lf *levelsFlag) String() string {
+	if lf == nil {
+		return ""
+	}
+	pairs := make([]string, 0, len(*lf))
+	for name, level := range *lf {
+		pairs = append(pairs, name+"="+level)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (lf *levelsFlag) Set(value string) error {
+	name, level, found := strings.Cut(value, "=")
+	if !found || name == "" {
+		return fmt.Errorf("expected <component>=<level>: '%s'", value)
+	}
+	if *lf == nil {
+		*lf = make(map[string]string)
+	}
+	(*lf)[strings.TrimSpace(name)] = strings.TrimSpace(level)
+	return nil
+}
@@ -0,0 +1,77 @@
+package log
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/rs/zerolog"
+)
+
+// Field names used for IDs added to context loggers.
+var (
+	RequestIDFieldName = "requestId"
+	TraceIDFieldName   = "traceId"
+)
+
+type contextKey uint8
+
+const (
+	requestIDKey contextKey = iota
+	traceIDKey
+)
+
+// Ctx returns the logger attached to the context by WithContext
+// (or by zerolog.Logger.WithContext) or the default logger if there is none.
+func Ctx(ctx context.Context) *zerolog.Logger {
+	// Without an attached logger zerolog.Ctx returns the same fallback logger for any context.
+	if logger := zerolog.Ctx(ctx); logger != zerolog.Ctx(context.Background()) {
+		return logger
+	}
+	return Logger()
+}
+
+// WithContext returns a copy of the context with the logger attached.
+func WithContext(ctx context.Context, logger *zerolog.Logger) context.Context {
+	return logger.WithContext(ctx)
+}
+
+// WithRequestID returns a copy of the context containing the request ID
+// with a logger that adds the request ID to each log record.
+// The logger is derived from the logger returned by Ctx(ctx).
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return withID(ctx, requestIDKey, RequestIDFieldName, id)
+}
+
+// RequestID returns the request ID from the context or an empty string.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// WithTraceID returns a copy of the context containing the trace ID
+// with a logger that adds the trace ID to each log record.
+// The logger is derived from the logger returned by Ctx(ctx).
+func WithTraceID(ctx context.Context, id string) context.Context {
+	return withID(ctx, traceIDKey, TraceIDFieldName, id)
+}
+
+// TraceID returns the trace ID from the context or an empty string.
+func TraceID(ctx context.Context) string {
+	id, _ := ctx.Value(traceIDKey).(string)
+	return id
+}
+
+func withID(ctx context.Context, key contextKey, field, id string) context.Context {
+	logger := Ctx(ctx).With().Str(field, id).Logger()
+	return logger.WithContext(context.WithValue(ctx, key, id))
+}
+
+// NewID returns a random 128-bit ID as 32 hexadecimal characters
+// suitable for use as a request or trace ID.
+func NewID() string {
+	id := make([]byte, 16)
+	// Failure of the system random source is not expected in practice.
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,49 @@
+package log
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCtx(t *testing.T) {
+	buffer := captureLevels(t)
+	assert.Equal(t, Logger(), Ctx(context.Background()))
+
+	logger := Logger().With().Str("attached", "yes").Logger()
+	ctx := WithContext(context.Background(), &logger)
+	assert.Equal(t, &logger, Ctx(ctx))
+	Ctx(ctx).Info().Msg("attached")
+	assert.Equal(t, `{"level":"info","attached":"yes","message":"attached"}`+"\n", buffer.String())
+}
+
+func TestWithRequestID(t *testing.T) {
+	buffer := captureLevels(t)
+	ctx := context.Background()
+	assert.Empty(t, RequestID(ctx))
+	assert.Empty(t, TraceID(ctx))
+
+	ctx = WithTraceID(WithRequestID(ctx, "request-1"), "trace-1")
+	assert.Equal(t, "request-1", RequestID(ctx))
+	assert.Equal(t, "trace-1", TraceID(ctx))
+	Ctx(ctx).Info().Msg("with IDs")
+	assert.Equal(t, `{"level":"info","requestId":"request-1","traceId":"trace-1","message":"with IDs"}`+"\n",
+		buffer.String())
+}
+
+func TestNewID(t *testing.T) {
+	id := NewID()
+	assert.Len(t, id, 32)
+	assert.Regexp(t, "^[0-9a-f]+$", id)
+	assert.NotEqual(t, id, NewID())
+}
+
+func ExampleWithRequestID() {
+	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stdout, NoColor: true, PartsExclude: []string{"time"}})
+	ctx := WithRequestID(WithContext(context.Background(), &logger), "abc123")
+	Ctx(ctx).Info().Msg("Handling request")
+	// Output: INF Handling request requestId=abc123
+}
@@ -0,0 +1,293 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// ComponentFieldName is the field name used for the component name in log records.
+var ComponentFieldName = "component"
+
+var (
+	levelLock       sync.RWMutex
+	defaultLevel    zerolog.Level
+	levelSet        bool
+	globalCaptured  bool
+	componentLevels = make(map[string]zerolog.Level)
+)
+
+// Level returns the default log level.
+// Until SetLevel is called this is the zerolog global level set by the application.
+func Level() zerolog.Level {
+	levelLock.RLock()
+	defer levelLock.RUnlock()
+	return currentDefault()
+}
+
+// SetLevel sets the default log level for the default logger and component loggers
+// that have no level override.
+// The default logger filters events using a zerolog.Sampler (see SetLogger),
+// so SetLevel may be called at any time without replacing the default logger.
+func SetLevel(level zerolog.Level) {
+	levelLock.Lock()
+	defer levelLock.Unlock()
+	defaultLevel = level
+	levelSet = true
+	updateGlobalLevel()
+}
+
+// ComponentLevel returns the log level for the named component
+// and true if the component has a level override,
+// otherwise the default level and false.
+func ComponentLevel(name string) (zerolog.Level, bool) {
+	levelLock.RLock()
+	defer levelLock.RUnlock()
+	level, found := componentLevels[name]
+	if !found {
+		level = currentDefault()
+	}
+	return level, found
+}
+
+// ComponentLevels returns the level overrides by component name.
+func ComponentLevels() map[string]zerolog.Level {
+	levelLock.RLock()
+	defer levelLock.RUnlock()
+	levels := make(map[string]zerolog.Level, len(componentLevels))
+	for name, level := range componentLevels {
+		levels[name] = level
+	}
+	return levels
+}
+
+// SetComponentLevel overrides the log level for loggers of the named component
+// (see ComponentLogger), which may be lower or higher than the default level.
+func SetComponentLevel(name string, level zerolog.Level) {
+	levelLock.Lock()
+	defer levelLock.Unlock()
+	componentLevels[name] = level
+	updateGlobalLevel()
+}
+
+// ClearComponentLevel removes the log level override for the named component.
+func ClearComponentLevel(name string) {
+	levelLock.Lock()
+	defer levelLock.Unlock()
+	delete(componentLevels, name)
+	updateGlobalLevel()
+}
+
+// currentDefault returns the default level.
+// Until SetLevel is called this is the zerolog global level set by the application,
+// captured before the global level is first changed by this package.
+// Must be called with the level lock held.
+func currentDefault() zerolog.Level {
+	if levelSet || globalCaptured {
+		return defaultLevel
+	}
+	return zerolog.GlobalLevel()
+}
+
+// updateGlobalLevel sets the zerolog global level to the lowest level in use
+// so that events for components with lower levels are not filtered out.
+// The default logger filters its own events at the default level (see levelSampler).
+// Must be called with the level lock held.
+func updateGlobalLevel() {
+	if !levelSet && !globalCaptured {
+		defaultLevel = zerolog.GlobalLevel()
+		globalCaptured = true
+	}
+	lowest := defaultLevel
+	for _, level := range componentLevels {
+		if level < lowest {
+			lowest = level
+		}
+	}
+	zerolog.SetGlobalLevel(lowest)
+}
+
+// levelSampler is a zerolog.Sampler that filters events below the level of a component
+// or below the default level if the component name is empty.
+// Samplers are consulted when each event is created and are replaced (not accumulated)
+// by zerolog.Logger.Sample, so the sampler of a component logger replaces that of the default logger.
+// Disabling sampling via zerolog.DisableSampling disables this filtering.
+type levelSampler string
+
+// Sample implements the zerolog.Sampler interface.
+func (ls levelSampler) Sample(level zerolog.Level) bool {
+	if level == zerolog.NoLevel {
+		return true
+	}
+	levelLock.RLock()
+	defer levelLock.RUnlock()
+	minimum, found := componentLevels[string(ls)]
+	if ls == "" || !found {
+		minimum = currentDefault()
+	}
+	return level >= minimum
+}
+
+//////////////////////////////////////////////////////////////////////////
+
+var (
+	componentLock    sync.Mutex
+	componentLoggers = make(map[string]*zerolog.Logger)
+	loggerGeneration uint64
+)
+
+// ComponentLogger returns a logger derived from the default logger for the named component.
+// Log records have a component field with the name
+// and are filtered by the component's level (see SetComponentLevel),
+// which may be changed at any time.
+//
+// The same logger is returned for each call with the same name.
+// It is derived again when the default logger is replaced by SetLogger, Console, or ConsoleOrFile.Setup,
+// which should be done before logging starts as the logger is changed in place.
+// Loggers derived from the component logger (e.g. via With()) are not changed.
+func ComponentLogger(name string) *zerolog.Logger {
+	componentLock.Lock()
+	defer componentLock.Unlock()
+	if logger, found := componentLoggers[name]; found {
+		return logger
+	}
+	logger := newComponentLogger(name)
+	componentLoggers[name] = &logger
+	return &logger
+}
+
+// newComponentLogger derives a logger for the named component from the default logger.
+func newComponentLogger(name string) zerolog.Logger {
+	return log.Logger.Level(zerolog.TraceLevel).Sample(levelSampler(name)).
+		With().Str(ComponentFieldName, name).Logger()
+}
+
+func init() {
+	log.Logger = log.Logger.Sample(levelSampler(""))
+}
+
+// replaceLogger sets the default logger, filtered at the default level by a levelSampler,
+// and derives the component loggers from it again.
+func replaceLogger(logger zerolog.Logger) {
+	componentLock.Lock()
+	defer componentLock.Unlock()
+	log.Logger = logger.Sample(levelSampler(""))
+	for name, componentLogger := range componentLoggers {
+		*componentLogger = newComponentLogger(name)
+	}
+	loggerGeneration++
+}
+
+// generation returns a number that changes whenever the default logger is replaced.
+func generation() uint64 {
+	componentLock.Lock()
+	defer componentLock.Unlock()
+	return loggerGeneration
+}
+
+//////////////////////////////////////////////////////////////////////////
+
+// levelState is the JSON representation of the log levels used by LevelHandler.
+type levelState struct {
+	Level      string            `json:"level"`
+	Components map[string]string `json:"components"`
+}
+
+// LevelHandler returns an http.Handler for viewing and changing log levels at runtime.
+// GET returns the default level and component overrides as JSON:
+//
+//	{"level": "info", "components": {"db": "debug"}}
+//
+// PUT or POST changes levels from a JSON object of the same form.
+// Either field may be omitted and an empty component level removes the override.
+// Levels may also be specified as query parameters,
+// 'level' for the default level and 'component.<name>' for components.
+// The response to all requests is the current state.
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			var state levelState
+			if r.ContentLength != 0 && r.Body != nil {
+				if err := json.NewDecoder(r.Body).Decode(&state); err != nil {
+					http.Error(w, fmt.Sprintf("decode levels: %s", err), http.StatusBadRequest)
+					return
+				}
+			}
+			if state.Components == nil {
+				state.Components = make(map[string]string)
+			}
+			for key, values := range r.URL.Query() {
+				if key == "level" {
+					state.Level = values[0]
+				} else if name := strings.TrimPrefix(key, "component."); name != key {
+					state.Components[name] = values[0]
+				}
+			}
+			if err := applyLevels(state); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(currentLevels())
+	})
+}
+
+// applyLevels validates all levels in the state before changing any of them.
+func applyLevels(state levelState) error {
+	var level zerolog.Level
+	var err error
+	if state.Level != "" {
+		if level, err = zerolog.ParseLevel(state.Level); err != nil {
+			return fmt.Errorf("default level: %w", err)
+		}
+	}
+	components := make(map[string]*zerolog.Level, len(state.Components))
+	names := make([]string, 0, len(state.Components))
+	for name, text := range state.Components {
+		names = append(names, name)
+		if text != "" {
+			componentLevel, err := zerolog.ParseLevel(text)
+			if err != nil {
+				return fmt.Errorf("component '%s' level: %w", name, err)
+			}
+			components[name] = &componentLevel
+		}
+	}
+	if state.Level != "" {
+		SetLevel(level)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if components[name] == nil {
+			ClearComponentLevel(name)
+		} else {
+			SetComponentLevel(name, *components[name])
+		}
+	}
+	return nil
+}
+
+// currentLevels returns the current default level and component overrides.
+func currentLevels() levelState {
+	state := levelState{
+		Level:      Level().String(),
+		Components: make(map[string]string),
+	}
+	for name, level := range ComponentLevels() {
+		state.Components[name] = level.String()
+	}
+	return state
+}
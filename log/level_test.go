@@ -0,0 +1,207 @@
+package log
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureLevels sends the default logger to a buffer
+// and restores the logger and log levels when the test is finished.
+func captureLevels(t *testing.T) *bytes.Buffer {
+	buffer := &bytes.Buffer{}
+	logger := *Logger()
+	SetLogger(zerolog.New(buffer))
+	t.Cleanup(func() {
+		SetLogger(logger)
+		levelLock.Lock()
+		defer levelLock.Unlock()
+		componentLevels = make(map[string]zerolog.Level)
+		levelSet, globalCaptured = false, false
+		zerolog.SetGlobalLevel(zerolog.TraceLevel)
+	})
+	return buffer
+}
+
+func TestSetLevel(t *testing.T) {
+	buffer := captureLevels(t)
+	SetLevel(zerolog.WarnLevel)
+	assert.Equal(t, zerolog.WarnLevel, Level())
+	assert.Equal(t, zerolog.WarnLevel, zerolog.GlobalLevel())
+	Info().Msg("hidden")
+	Warn().Msg("shown")
+	assert.Equal(t, `{"level":"warn","message":"shown"}`+"\n", buffer.String())
+}
+
+func TestSetLevel_componentBelow(t *testing.T) {
+	buffer := captureLevels(t)
+	SetLevel(zerolog.WarnLevel)
+	SetComponentLevel("db", zerolog.DebugLevel)
+	assert.Equal(t, zerolog.DebugLevel, zerolog.GlobalLevel())
+	Info().Msg("hidden")
+	ComponentLogger("db").Debug().Msg("shown")
+	assert.Equal(t, `{"level":"debug","component":"db","message":"shown"}`+"\n", buffer.String())
+}
+
+func TestSetComponentLevel_globalLevel(t *testing.T) {
+	buffer := captureLevels(t)
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	assert.Equal(t, zerolog.InfoLevel, Level())
+	SetComponentLevel("web", zerolog.ErrorLevel)
+	assert.Equal(t, zerolog.InfoLevel, zerolog.GlobalLevel())
+	assert.Equal(t, zerolog.InfoLevel, Level())
+	SetComponentLevel("db", zerolog.DebugLevel)
+	assert.Equal(t, zerolog.DebugLevel, zerolog.GlobalLevel())
+	ClearComponentLevel("db")
+	assert.Equal(t, zerolog.InfoLevel, zerolog.GlobalLevel())
+	Debug().Msg("hidden")
+	ComponentLogger("web").Info().Msg("hidden")
+	Info().Msg("shown")
+	assert.Equal(t, `{"level":"info","message":"shown"}`+"\n", buffer.String())
+}
+
+func TestSetLevel_concurrent(t *testing.T) {
+	captureLevels(t)
+	SetLogger(zerolog.New(io.Discard))
+	handler := LevelHandler()
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+					Info().Msg("default")
+					ComponentLogger("db").Debug().Msg("component")
+				}
+			}
+		}()
+	}
+	for i := 0; i < 100; i++ {
+		SetLevel(zerolog.Level(i % 4))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(
+			http.MethodPost, "/?level=info&component.db=debug", nil))
+	}
+	close(done)
+	wg.Wait()
+	assert.Equal(t, zerolog.InfoLevel, Level())
+}
+
+func TestComponentLogger(t *testing.T) {
+	buffer := captureLevels(t)
+	SetLevel(zerolog.InfoLevel)
+	db := ComponentLogger("db")
+	web := ComponentLogger("web")
+
+	SetComponentLevel("db", zerolog.DebugLevel)
+	SetComponentLevel("web", zerolog.ErrorLevel)
+	assert.Equal(t, zerolog.DebugLevel, zerolog.GlobalLevel())
+	level, found := ComponentLevel("db")
+	assert.True(t, found)
+	assert.Equal(t, zerolog.DebugLevel, level)
+	assert.Equal(t, map[string]zerolog.Level{"db": zerolog.DebugLevel, "web": zerolog.ErrorLevel}, ComponentLevels())
+
+	Debug().Msg("default hidden")
+	db.Debug().Msg("db shown")
+	web.Warn().Msg("web hidden")
+	web.Error().Msg("web shown")
+	db.Trace().Msg("db hidden")
+	ClearComponentLevel("web")
+	web.Info().Msg("web default")
+	level, found = ComponentLevel("web")
+	assert.False(t, found)
+	assert.Equal(t, zerolog.InfoLevel, level)
+
+	assert.Equal(t, ""+
+		`{"level":"debug","component":"db","message":"db shown"}`+"\n"+
+		`{"level":"error","component":"web","message":"web shown"}`+"\n"+
+		`{"level":"info","component":"web","message":"web default"}`+"\n",
+		buffer.String())
+}
+
+func TestComponentLogger_setLogger(t *testing.T) {
+	captureLevels(t)
+	early := ComponentLogger("early")
+	assert.Same(t, early, ComponentLogger("early"))
+	var local LocalLogger
+	local.SetComponent("early", "local")
+
+	buffer := &bytes.Buffer{}
+	SetLogger(zerolog.New(buffer))
+	early.Info().Msg("component")
+	local.Logger().Info().Msg("local")
+	assert.Equal(t, ""+
+		`{"level":"info","component":"early","message":"component"}`+"\n"+
+		`{"level":"info","component":"early","instance":"local","message":"local"}`+"\n",
+		buffer.String())
+}
+
+func TestLevelHandler(t *testing.T) {
+	captureLevels(t)
+	handler := LevelHandler()
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return recorder
+	}
+
+	response := serve(http.MethodGet, "/", "")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "application/json", response.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"level":"trace","components":{}}`, response.Body.String())
+
+	response = serve(http.MethodPut, "/", `{"level":"info","components":{"db":"debug","web":"warn"}}`)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.JSONEq(t, `{"level":"info","components":{"db":"debug","web":"warn"}}`, response.Body.String())
+	assert.Equal(t, zerolog.InfoLevel, Level())
+
+	response = serve(http.MethodPost, "/?level=warn&component.web=&component.api=error", "")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.JSONEq(t, `{"level":"warn","components":{"db":"debug","api":"error"}}`, response.Body.String())
+
+	response = serve(http.MethodPut, "/", `{"level":"loud","components":{"db":"trace"}}`)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.Contains(t, response.Body.String(), "default level")
+	response = serve(http.MethodPut, "/?component.db=quiet", "")
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.Contains(t, response.Body.String(), "component 'db' level")
+	response = serve(http.MethodPut, "/", `{`)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.Equal(t, map[string]zerolog.Level{"db": zerolog.DebugLevel, "api": zerolog.ErrorLevel}, ComponentLevels())
+
+	response = serve(http.MethodDelete, "/", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, response.Code)
+	assert.Equal(t, "GET, PUT, POST", response.Header().Get("Allow"))
+}
+
+func TestConsoleOrFile_level(t *testing.T) {
+	captureLevels(t)
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	cof := ConsoleOrFile{}
+	cof.AddFlagsToSet(flags, "")
+	require.NoError(t, flags.Parse([]string{
+		"-console", "-logLevel", "warn", "-logComponentLevel", "db=debug", "-logComponentLevel", "web = error"}))
+	assert.Equal(t, "db=debug,web=error", flags.Lookup("logComponentLevel").Value.String())
+	require.NoError(t, cof.Setup())
+	defer cof.CloseForDefer()
+	assert.Equal(t, zerolog.WarnLevel, Level())
+	assert.Equal(t, zerolog.DebugLevel, zerolog.GlobalLevel())
+	assert.Equal(t, map[string]zerolog.Level{"db": zerolog.DebugLevel, "web": zerolog.ErrorLevel}, ComponentLevels())
+
+	assert.Error(t, flags.Parse([]string{"-logComponentLevel", "nothing"}))
+	bad := ConsoleOrFile{Console: true, Level: "loud"}
+	assert.ErrorContains(t, bad.Setup(), "log levels: default level")
+}
@@ -1,13 +1,23 @@
 package log
 
 import (
+	"context"
+
 	"github.com/rs/zerolog"
 )
 
+// InstanceFieldName is the field name used for the instance ID in log records.
+var InstanceFieldName = "instance"
+
 // LocalLogger provides a logger mixin and associated functionality.
 // Embed this object within another object to provide an object-specific Logger.
+// Use SetComponent to log with component and instance fields
+// and level overrides for the component (see SetComponentLevel).
 type LocalLogger struct {
-	logger *zerolog.Logger
+	logger     *zerolog.Logger
+	component  string
+	instance   string
+	generation uint64
 }
 
 // Logger returns the local logger.
@@ -22,7 +32,9 @@ func (ll *LocalLogger) Logger() *zerolog.Logger {
 // by the specified default function.
 // Override LocalLogger.Logger() and call this with an appropriate function.
 func (ll *LocalLogger) LoggerWithFn(defaultFn func() *zerolog.Logger) *zerolog.Logger {
-	if ll.logger == nil {
+	if ll.component != "" && ll.generation != generation() {
+		ll.SetComponent(ll.component, ll.instance)
+	} else if ll.logger == nil {
 		ll.logger = defaultFn()
 	}
 
@@ -32,4 +44,48 @@ func (ll *LocalLogger) LoggerWithFn(defaultFn func() *zerolog.Logger) *zerolog.L
 // SetLogger sets the local logger.
 func (ll *LocalLogger) SetLogger(logger *zerolog.Logger) {
 	ll.logger = logger
+	ll.component = ""
+}
+
+// SetComponent sets the local logger to a logger for the named component (see ComponentLogger).
+// If instance is not empty the logger adds it to each log record
+// to distinguish between objects of the same component.
+// The local logger is derived again if the default logger is replaced
+// by SetLogger, Console, or ConsoleOrFile.Setup.
+func (ll *LocalLogger) SetComponent(component, instance string) {
+	ll.component, ll.instance, ll.generation = component, instance, generation()
+	logger := ComponentLogger(component)
+	if instance != "" {
+		child := logger.With().Str(InstanceFieldName, instance).Logger()
+		logger = &child
+	}
+	ll.logger = logger
+}
+
+// ChildLogger returns a logger derived from the local logger that adds
+// the specified fields to each log record.
+// Fields are specified as alternating keys and values or as a map
+// as for zerolog.Context.Fields().
+func (ll *LocalLogger) ChildLogger(fields interface{}) *zerolog.Logger {
+	child := ll.Logger().With().Fields(fields).Logger()
+	return &child
+}
+
+// LoggerCtx returns a logger derived from the local logger that adds
+// any request and trace IDs from the context (see WithRequestID and WithTraceID).
+func (ll *LocalLogger) LoggerCtx(ctx context.Context) *zerolog.Logger {
+	logger := ll.Logger()
+	requestID, traceID := RequestID(ctx), TraceID(ctx)
+	if requestID == "" && traceID == "" {
+		return logger
+	}
+	child := logger.With()
+	if requestID != "" {
+		child = child.Str(RequestIDFieldName, requestID)
+	}
+	if traceID != "" {
+		child = child.Str(TraceIDFieldName, traceID)
+	}
+	derived := child.Logger()
+	return &derived
 }
@@ -1,6 +1,7 @@
 package log
 
 import (
+	"context"
 	"testing"
 
 	"github.com/rs/zerolog"
@@ -85,3 +86,40 @@ func TestLocalSetLogger(t *testing.T) {
 	assert.NotNil(t, w.logger)
 	assert.Equal(t, &logger, w.logger)
 }
+
+func TestLocalLogger_SetComponent(t *testing.T) {
+	buffer := captureLevels(t)
+	SetLevel(zerolog.InfoLevel)
+	SetComponentLevel("cache", zerolog.DebugLevel)
+	var w withLocalLogger
+	w.SetComponent("cache", "primary")
+	w.Logger().Debug().Msg("component level")
+	var other withLocalLogger
+	other.SetComponent("other", "")
+	other.Logger().Debug().Msg("default level")
+	assert.Equal(t, `{"level":"debug","component":"cache","instance":"primary","message":"component level"}`+"\n",
+		buffer.String())
+}
+
+func TestLocalLogger_ChildLogger(t *testing.T) {
+	buffer := captureLevels(t)
+	var w withLocalLogger
+	w.ChildLogger([]interface{}{"key", "value", "number", 7}).Info().Msg("pairs")
+	w.ChildLogger(map[string]interface{}{"mapped": true}).Info().Msg("map")
+	assert.Equal(t, ""+
+		`{"level":"info","key":"value","number":7,"message":"pairs"}`+"\n"+
+		`{"level":"info","mapped":true,"message":"map"}`+"\n",
+		buffer.String())
+}
+
+func TestLocalLogger_LoggerCtx(t *testing.T) {
+	buffer := captureLevels(t)
+	var w withLocalLogger
+	assert.Equal(t, w.Logger(), w.LoggerCtx(context.Background()))
+	w.LoggerCtx(WithTraceID(context.Background(), "trace-1")).Info().Msg("traced")
+	w.LoggerCtx(WithRequestID(context.Background(), "request-1")).Info().Msg("requested")
+	assert.Equal(t, ""+
+		`{"level":"info","traceId":"trace-1","message":"traced"}`+"\n"+
+		`{"level":"info","requestId":"request-1","message":"requested"}`+"\n",
+		buffer.String())
+}
@@ -13,8 +13,10 @@ func Logger() *zerolog.Logger {
 }
 
 // SetLogger sets the default zerolog logger to the specified logger value.
+// Component loggers are derived again from the new default logger (see ComponentLogger).
+// The logger's sampler is replaced by one that filters events at the default level (see SetLevel).
 func SetLogger(logger zerolog.Logger) {
-	log.Logger = logger
+	replaceLogger(logger)
 }
 
 // Debug returns a debug event from the default zerolog logger.
@@ -39,7 +41,7 @@ func Info() *zerolog.Event {
 
 // Panic returns a panic event from the default zerolog logger.
 func Panic() *zerolog.Event {
-	return log.Logger.Info()
+	return log.Logger.Panic()
 }
 
 // Trace returns a trace event from the default zerolog logger.
@@ -30,3 +30,11 @@ func TestSetLogger(t *testing.T) {
 	require.Equal(t, Logger(), &newLogger)
 	assert.IsType(t, &zerolog.Logger{}, Logger())
 }
+
+func TestPanic(t *testing.T) {
+	buffer := captureLevels(t)
+	assert.PanicsWithValue(t, "Panic message", func() {
+		Panic().Msg("Panic message")
+	})
+	assert.Equal(t, `{"level":"panic","message":"Panic message"}`+"\n", buffer.String())
+}
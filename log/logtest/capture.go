@@ -0,0 +1,204 @@
+package logtest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/madkins23/go-utils/log"
+)
+
+// Event is a log record parsed from JSON.
+type Event map[string]interface{}
+
+// Level returns the level of the event.
+func (e Event) Level() string {
+	level, _ := e[zerolog.LevelFieldName].(string)
+	return level
+}
+
+// Message returns the message of the event.
+func (e Event) Message() string {
+	message, _ := e[zerolog.MessageFieldName].(string)
+	return message
+}
+
+//////////////////////////////////////////////////////////////////////////
+
+// Capture collects log output in a buffer for examination by tests.
+// Capture is safe for concurrent use.
+type Capture struct {
+	t      testing.TB
+	lock   sync.Mutex
+	buffer bytes.Buffer
+	logger zerolog.Logger
+}
+
+func newCapture(t testing.TB) *Capture {
+	c := &Capture{t: t}
+	c.logger = zerolog.New(c)
+	return c
+}
+
+// CaptureGlobal replaces the default logger with a logger that writes JSON to a Capture.
+// The previous logger is restored when the test and its subtests are finished.
+func CaptureGlobal(t testing.TB) *Capture {
+	c := newCapture(t)
+	previous := *log.Logger()
+	c.logger = c.logger.Level(previous.GetLevel())
+	log.SetLogger(c.logger)
+	t.Cleanup(func() {
+		log.SetLogger(previous)
+	})
+	return c
+}
+
+// CaptureLocal replaces the logger of a LocalLogger with a logger that writes JSON to a Capture.
+// The previous logger is restored when the test and its subtests are finished.
+func CaptureLocal(t testing.TB, local *log.LocalLogger) *Capture {
+	c := newCapture(t)
+	previous := local.Logger()
+	local.SetLogger(&c.logger)
+	t.Cleanup(func() {
+		local.SetLogger(previous)
+	})
+	return c
+}
+
+// Logger returns the capturing logger, e.g. to pass to code under test.
+func (c *Capture) Logger() *zerolog.Logger {
+	return &c.logger
+}
+
+// Write implements the io.Writer interface.
+func (c *Capture) Write(p []byte) (int, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.buffer.Write(p)
+}
+
+// Text returns the captured log output.
+func (c *Capture) Text() string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.buffer.String()
+}
+
+// Reset discards the captured log output.
+func (c *Capture) Reset() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.buffer.Reset()
+}
+
+// Events returns the captured log records parsed from JSON.
+// Lines that can't be parsed fail the test.
+func (c *Capture) Events() []Event {
+	c.t.Helper()
+	events := make([]Event, 0)
+	scanner := bufio.NewScanner(strings.NewReader(c.Text()))
+	scanner.Buffer(nil, 1024*1024)
+	for number := 1; scanner.Scan(); number++ {
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var event Event
+		if err := json.Unmarshal(line, &event); err != nil {
+			c.t.Errorf("parse log line %d '%s': %s", number, line, err)
+			continue
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// HasEvent asserts that an event was logged at the specified level with the specified message
+// (any message if empty) and fields, which are specified as alternating names and values.
+// Field values are compared after conversion to JSON so that e.g. int values match.
+// Returns true if a matching event was found, otherwise fails the test and returns false.
+func (c *Capture) HasEvent(level zerolog.Level, message string, fields ...interface{}) bool {
+	c.t.Helper()
+	expected, err := expectedFields(fields)
+	if err != nil {
+		c.t.Errorf("HasEvent: %s", err)
+		return false
+	}
+	events := c.Events()
+	for _, event := range events {
+		if event.Level() == level.String() && (message == "" || event.Message() == message) && matches(event, expected) {
+			return true
+		}
+	}
+	c.t.Errorf("no %s event '%s' with fields %v in %d events:\n%s", level, message, fields, len(events), c.Text())
+	return false
+}
+
+// NoErrorsLogged asserts that no events were logged at error level or above.
+// Returns true if there were no such events, otherwise fails the test and returns false.
+func (c *Capture) NoErrorsLogged() bool {
+	c.t.Helper()
+	errorEvents := make([]string, 0)
+	for _, event := range c.Events() {
+		if level, err := zerolog.ParseLevel(event.Level()); err == nil &&
+			level >= zerolog.ErrorLevel && level <= zerolog.PanicLevel {
+			errorEvents = append(errorEvents, fmt.Sprintf("%s: %s", event.Level(), event.Message()))
+		}
+	}
+	if len(errorEvents) > 0 {
+		c.t.Errorf("%d error events logged:\n  %s", len(errorEvents), strings.Join(errorEvents, "\n  "))
+		return false
+	}
+	return true
+}
+
+// expectedFields converts alternating names and values into a map of JSON values.
+func expectedFields(fields []interface{}) (map[string]interface{}, error) {
+	if len(fields)%2 != 0 {
+		return nil, fmt.Errorf("odd number of field arguments %v", fields)
+	}
+	expected := make(map[string]interface{}, len(fields)/2)
+	for i := 0; i < len(fields); i += 2 {
+		name, ok := fields[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("field name %v is not a string", fields[i])
+		}
+		value, err := jsonValue(fields[i+1])
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", name, err)
+		}
+		expected[name] = value
+	}
+	return expected, nil
+}
+
+// jsonValue returns the value as it would be parsed from JSON.
+func jsonValue(value interface{}) (interface{}, error) {
+	if err, ok := value.(error); ok {
+		value = err.Error()
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var parsed interface{}
+	err = json.Unmarshal(data, &parsed)
+	return parsed, err
+}
+
+// matches returns true if the event contains all the expected fields.
+func matches(event Event, expected map[string]interface{}) bool {
+	for name, value := range expected {
+		if actual, found := event[name]; !found || !reflect.DeepEqual(actual, value) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,107 @@
+package logtest
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/madkins23/go-utils/log"
+)
+
+// mockT records test failures and cleanup functions.
+type mockT struct {
+	testing.TB
+	failures []string
+	cleanups []func()
+}
+
+func (m *mockT) Helper() {}
+
+func (m *mockT) Errorf(format string, args ...interface{}) {
+	m.failures = append(m.failures, fmt.Sprintf(format, args...))
+}
+
+func (m *mockT) Cleanup(fn func()) {
+	m.cleanups = append(m.cleanups, fn)
+}
+
+func (m *mockT) cleanup() {
+	for i := len(m.cleanups) - 1; i >= 0; i-- {
+		m.cleanups[i]()
+	}
+}
+
+func TestCaptureGlobal(t *testing.T) {
+	original := log.Logger()
+	mock := &mockT{}
+	capture := CaptureGlobal(mock)
+	log.Info().Str("user", "alice").Int("count", 3).Msg("Logged in")
+	log.Warn().Err(errors.New("slow")).Msg("Delayed")
+
+	events := capture.Events()
+	require.Len(t, events, 2)
+	assert.Equal(t, "info", events[0].Level())
+	assert.Equal(t, "Logged in", events[0].Message())
+	assert.Equal(t, 3.0, events[0]["count"])
+
+	assert.True(t, capture.HasEvent(zerolog.InfoLevel, "Logged in", "user", "alice", "count", 3))
+	assert.True(t, capture.HasEvent(zerolog.InfoLevel, "", "user", "alice"))
+	assert.True(t, capture.HasEvent(zerolog.WarnLevel, "Delayed", "error", errors.New("slow")))
+	assert.True(t, capture.NoErrorsLogged())
+	assert.Empty(t, mock.failures)
+
+	mock.cleanup()
+	assert.Equal(t, *original, *log.Logger())
+}
+
+func TestCapture_failures(t *testing.T) {
+	mock := &mockT{}
+	capture := CaptureGlobal(mock)
+	defer mock.cleanup()
+	log.Info().Int("count", 3).Msg("Counted")
+	log.Error().Msg("Failed")
+
+	assert.False(t, capture.HasEvent(zerolog.InfoLevel, "Counted", "count", 4))
+	assert.False(t, capture.HasEvent(zerolog.WarnLevel, "Counted"))
+	assert.False(t, capture.HasEvent(zerolog.InfoLevel, "Counted", "count"))
+	assert.False(t, capture.HasEvent(zerolog.InfoLevel, "Counted", 3, "count"))
+	assert.False(t, capture.NoErrorsLogged())
+	require.Len(t, mock.failures, 5)
+	assert.Contains(t, mock.failures[0], "no info event 'Counted' with fields [count 4] in 2 events")
+	assert.Contains(t, mock.failures[1], "no warn event 'Counted'")
+	assert.Contains(t, mock.failures[2], "odd number of field arguments")
+	assert.Contains(t, mock.failures[3], "field name 3 is not a string")
+	assert.Equal(t, "1 error events logged:\n  error: Failed", mock.failures[4])
+
+	mock.failures = nil
+	_, _ = capture.Write([]byte("not json\n"))
+	assert.Len(t, capture.Events(), 2)
+	require.Len(t, mock.failures, 1)
+	assert.Contains(t, mock.failures[0], "parse log line 3 'not json'")
+
+	capture.Reset()
+	assert.Empty(t, capture.Text())
+	assert.Empty(t, capture.Events())
+}
+
+type localObject struct {
+	log.LocalLogger
+}
+
+func TestCaptureLocal(t *testing.T) {
+	var object localObject
+	object.SetComponent("object", "one")
+	previous := object.Logger()
+	mock := &mockT{}
+	capture := CaptureLocal(mock, &object.LocalLogger)
+	object.Logger().Debug().Msg("Local")
+	assert.True(t, capture.HasEvent(zerolog.DebugLevel, "Local"))
+	assert.Equal(t, capture.Logger(), object.Logger())
+	mock.cleanup()
+	assert.Equal(t, previous, object.Logger())
+	assert.Empty(t, mock.failures)
+}
@@ -0,0 +1,12 @@
+// Package logtest provides utilities for testing code that logs using the log package.
+//
+// Log output is captured as JSON and parsed into events
+// so that tests need not parse console log text:
+//
+//	func TestLogin(t *testing.T) {
+//	    capture := logtest.CaptureGlobal(t)
+//	    login("alice")
+//	    capture.HasEvent(zerolog.InfoLevel, "Logged in", "user", "alice")
+//	    capture.NoErrorsLogged()
+//	}
+package logtest
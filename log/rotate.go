@@ -0,0 +1,404 @@
+package log
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/madkins23/go-utils/clock"
+)
+
+var _ io.WriteCloser = &RotatingFile{}
+
+// backupTimeFormat is used to mark rotated log files with the time of rotation.
+const backupTimeFormat = "20060102T150405.000"
+
+var errFileClosed = errors.New("log file closed")
+
+// RotatingFile is an io.WriteCloser for a log file that is rotated
+// when it reaches a maximum size and/or at regular intervals.
+// Rotated files are renamed with a timestamp between the base name and extension
+// (e.g. 'app-20240102T030405.000.log') and may be compressed with gzip.
+// Old rotated files are removed based on their number and age.
+// The file may also be reopened (e.g. on SIGHUP after rotation by an external logrotate).
+// Configure the exported fields before the first Write.
+// RotatingFile is safe for concurrent use.
+type RotatingFile struct {
+	// Path of the log file.
+	Path string
+
+	// MaxSize in bytes at which the file is rotated, zero for no maximum size.
+	MaxSize int64
+
+	// Interval at which the file is rotated, zero for no time-based rotation.
+	// Rotation times are multiples of the interval since the zero time (see time.Time.Truncate),
+	// so an interval of 24 hours rotates at midnight UTC.
+	Interval time.Duration
+
+	// MaxBackups is the maximum number of rotated files to keep, zero to keep all.
+	MaxBackups int
+
+	// MaxAge is the maximum age of rotated files to keep, zero to keep all.
+	MaxAge time.Duration
+
+	// Compress rotated files with gzip.
+	Compress bool
+
+	// Clock used for rotation times.
+	// If nil the clock.Default object is used.
+	Clock clock.Clock
+
+	lock     sync.Mutex
+	file     *os.File
+	size     int64
+	rotateAt time.Time
+	closed   bool
+	signals  chan os.Signal
+	previous chan struct{}
+	pending  sync.WaitGroup
+}
+
+// Write implements the io.Writer interface.
+// The file is opened on the first Write and rotated before a Write that would exceed
+// the maximum size or that occurs after the rotation interval has passed.
+func (rf *RotatingFile) Write(p []byte) (int, error) {
+	rf.lock.Lock()
+	defer rf.lock.Unlock()
+	if rf.closed {
+		return 0, errFileClosed
+	}
+	if rf.file == nil {
+		if err := rf.open(); err != nil {
+			return 0, err
+		}
+	}
+	if rf.MaxSize > 0 && rf.size > 0 && rf.size+int64(len(p)) > rf.MaxSize ||
+		rf.Interval > 0 && !rf.now().Before(rf.rotateAt) {
+		if err := rf.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := rf.file.Write(p)
+	rf.size += int64(n)
+	return n, err
+}
+
+// Open the file if it is not already open.
+// Calling Open is optional as the file is opened on the first Write,
+// but it reports any error opening the file immediately.
+func (rf *RotatingFile) Open() error {
+	rf.lock.Lock()
+	defer rf.lock.Unlock()
+	if rf.file != nil {
+		return nil
+	}
+	return rf.open()
+}
+
+// Rotate the file immediately.
+func (rf *RotatingFile) Rotate() error {
+	rf.lock.Lock()
+	defer rf.lock.Unlock()
+	if rf.closed {
+		return errFileClosed
+	}
+	return rf.rotate()
+}
+
+// Reopen closes and reopens the file without rotating it.
+// Use this after the file has been moved by an external program such as logrotate.
+func (rf *RotatingFile) Reopen() error {
+	rf.lock.Lock()
+	defer rf.lock.Unlock()
+	if rf.closed {
+		return errFileClosed
+	}
+	if err := rf.closeFile(); err != nil {
+		return err
+	}
+	return rf.open()
+}
+
+// ReopenOnSignal reopens the file whenever one of the specified signals
+// (e.g. syscall.SIGHUP) is received until the file is closed.
+func (rf *RotatingFile) ReopenOnSignal(signals ...os.Signal) {
+	rf.lock.Lock()
+	defer rf.lock.Unlock()
+	if rf.signals != nil || rf.closed {
+		return
+	}
+	rf.signals = make(chan os.Signal, 1)
+	signal.Notify(rf.signals, signals...)
+	go func(channel chan os.Signal) {
+		for range channel {
+			if err := rf.Reopen(); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "reopen log file '%s': %s\n", rf.Path, err)
+			}
+		}
+	}(rf.signals)
+}
+
+// Close the file, stop handling signals,
+// and wait for any compression and removal of rotated files to finish.
+func (rf *RotatingFile) Close() error {
+	rf.lock.Lock()
+	if rf.signals != nil {
+		signal.Stop(rf.signals)
+		close(rf.signals)
+		rf.signals = nil
+	}
+	rf.closed = true
+	err := rf.closeFile()
+	rf.lock.Unlock()
+	rf.pending.Wait()
+	return err
+}
+
+//////////////////////////////////////////////////////////////////////////
+
+// Rotation configures the rotation of a log file.
+//
+// This struct has been configured with JSON and YAML struct tags
+// and may be embedded in other configuration structs.
+type Rotation struct {
+	// Maximum size of logfile in megabytes before it is rotated, zero for no maximum.
+	MaxSize int `json:"maxSize" yaml:"maxSize"`
+
+	// Interval at which logfile is rotated (e.g. "24h"), empty for no time-based rotation.
+	RotateEvery string `json:"rotateEvery" yaml:"rotateEvery"`
+
+	// Maximum number of rotated logfiles to keep, zero to keep all.
+	MaxBackups int `json:"maxBackups" yaml:"maxBackups"`
+
+	// Maximum age of rotated logfiles to keep (e.g. "720h"), empty to keep all.
+	MaxAge string `json:"maxAge" yaml:"maxAge"`
+
+	// True to compress rotated logfiles with gzip.
+	Compress bool `json:"compress" yaml:"compress"`
+
+	// True to reopen logfile on SIGHUP, e.g. after it has been rotated by logrotate.
+	ReopenOnHUP bool `json:"reopenOnHup" yaml:"reopenOnHup"`
+}
+
+// OpenFile opens the specified log file configured for rotation.
+func (r *Rotation) OpenFile(path string) (*RotatingFile, error) {
+	file := &RotatingFile{
+		Path:       path,
+		MaxSize:    int64(r.MaxSize) * 1024 * 1024,
+		MaxBackups: r.MaxBackups,
+		Compress:   r.Compress,
+	}
+	var err error
+	if r.RotateEvery != "" {
+		if file.Interval, err = time.ParseDuration(r.RotateEvery); err != nil {
+			return nil, fmt.Errorf("parse rotation interval: %w", err)
+		}
+	}
+	if r.MaxAge != "" {
+		if file.MaxAge, err = time.ParseDuration(r.MaxAge); err != nil {
+			return nil, fmt.Errorf("parse maximum age: %w", err)
+		}
+	}
+	if err = file.Open(); err != nil {
+		return nil, err
+	}
+	if r.ReopenOnHUP {
+		file.ReopenOnSignal(syscall.SIGHUP)
+	}
+	return file, nil
+}
+
+//////////////////////////////////////////////////////////////////////////
+
+// open the file in append mode, creating it if necessary.
+func (rf *RotatingFile) open() error {
+	file, err := os.OpenFile(rf.Path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		return fmt.Errorf("open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("stat log file: %w", err)
+	}
+	rf.file = file
+	rf.size = info.Size()
+	if rf.Interval > 0 {
+		rf.rotateAt = rf.now().Truncate(rf.Interval).Add(rf.Interval)
+	}
+	return nil
+}
+
+func (rf *RotatingFile) closeFile() error {
+	if rf.file == nil {
+		return nil
+	}
+	err := rf.file.Close()
+	rf.file = nil
+	if err != nil {
+		return fmt.Errorf("close log file: %w", err)
+	}
+	return nil
+}
+
+// rotate renames the current file (unless it is empty), opens a new file,
+// and starts compression and removal of old files in the background.
+// Background work for each rotation is done in order.
+func (rf *RotatingFile) rotate() error {
+	if err := rf.closeFile(); err != nil {
+		return err
+	}
+	now := rf.now()
+	backup := ""
+	if rf.size > 0 && exists(rf.Path) {
+		backup = rf.backupName(now)
+		if err := os.Rename(rf.Path, backup); err != nil {
+			return fmt.Errorf("rename log file: %w", err)
+		}
+	}
+	if err := rf.open(); err != nil {
+		return err
+	}
+	previous := rf.previous
+	done := make(chan struct{})
+	rf.previous = done
+	rf.pending.Add(1)
+	go func() {
+		defer rf.pending.Done()
+		defer close(done)
+		if previous != nil {
+			<-previous
+		}
+		if err := rf.compressAndRemove(backup, now); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "rotate log file '%s': %s\n", rf.Path, err)
+		}
+	}()
+	return nil
+}
+
+// backupName returns an unused name for a file rotated at the specified time.
+func (rf *RotatingFile) backupName(now time.Time) string {
+	prefix, ext := rf.splitPath()
+	stamp := now.Format(backupTimeFormat)
+	name := prefix + stamp + ext
+	for i := 1; exists(name) || exists(name+".gz"); i++ {
+		name = fmt.Sprintf("%s%s-%d%s", prefix, stamp, i, ext)
+	}
+	return name
+}
+
+// splitPath returns the prefix (path without extension plus hyphen) and extension for rotated files.
+func (rf *RotatingFile) splitPath() (string, string) {
+	ext := filepath.Ext(rf.Path)
+	return strings.TrimSuffix(rf.Path, ext) + "-", ext
+}
+
+// compressAndRemove compresses the specified rotated file (if any and if configured)
+// and removes rotated files that are too old as of the time of rotation.
+func (rf *RotatingFile) compressAndRemove(backup string, now time.Time) error {
+	// The rotated file may have been removed by the previous cleanup.
+	if backup != "" && rf.Compress && exists(backup) {
+		if err := compressFile(backup); err != nil {
+			return err
+		}
+	}
+	if rf.MaxBackups < 1 && rf.MaxAge <= 0 {
+		return nil
+	}
+	backups, err := rf.backups()
+	if err != nil {
+		return err
+	}
+	errs := make([]error, 0)
+	for i, b := range backups {
+		if rf.MaxBackups > 0 && i >= rf.MaxBackups ||
+			rf.MaxAge > 0 && now.Sub(b.rotated) > rf.MaxAge {
+			if err := os.Remove(b.path); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type backupFile struct {
+	path    string
+	rotated time.Time
+}
+
+// backups returns the rotated files, newest first.
+func (rf *RotatingFile) backups() ([]backupFile, error) {
+	prefix, ext := rf.splitPath()
+	matches, err := filepath.Glob(prefix + "*")
+	if err != nil {
+		return nil, fmt.Errorf("find rotated log files: %w", err)
+	}
+	backups := make([]backupFile, 0, len(matches))
+	for _, match := range matches {
+		stamp := strings.TrimPrefix(match, prefix)
+		stamp = strings.TrimSuffix(stamp, ".gz")
+		if !strings.HasSuffix(stamp, ext) {
+			continue
+		}
+		stamp = strings.TrimSuffix(stamp, ext)
+		if len(stamp) > len(backupTimeFormat) && stamp[len(backupTimeFormat)] == '-' {
+			// Remove the suffix added to avoid collisions.
+			stamp = stamp[:len(backupTimeFormat)]
+		}
+		if rotated, err := time.ParseInLocation(backupTimeFormat, stamp, time.Local); err == nil {
+			backups = append(backups, backupFile{path: match, rotated: rotated})
+		}
+	}
+	sort.SliceStable(backups, func(i, j int) bool {
+		if backups[i].rotated.Equal(backups[j].rotated) {
+			return backups[i].path > backups[j].path
+		}
+		return backups[i].rotated.After(backups[j].rotated)
+	})
+	return backups, nil
+}
+
+// now returns the current time from the clock.
+func (rf *RotatingFile) now() time.Time {
+	return clock.Or(rf.Clock).Now()
+}
+
+// compressFile compresses a file with gzip and removes the original.
+func compressFile(path string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open rotated log file: %w", err)
+	}
+	defer func() { _ = in.Close() }()
+	out, err := os.OpenFile(path+".gz", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return fmt.Errorf("create compressed log file: %w", err)
+	}
+	zipper := gzip.NewWriter(out)
+	if _, err = io.Copy(zipper, in); err == nil {
+		err = zipper.Close()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path + ".gz")
+		return fmt.Errorf("compress log file: %w", err)
+	}
+	_ = in.Close()
+	return os.Remove(path)
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
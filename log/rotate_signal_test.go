@@ -0,0 +1,33 @@
+//go:build !windows
+
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingFile_ReopenOnSignal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	rf := &RotatingFile{Path: path}
+	rf.ReopenOnSignal(syscall.SIGHUP)
+	defer func() { require.NoError(t, rf.Close()) }()
+	_, err := rf.Write([]byte("before\n"))
+	require.NoError(t, err)
+
+	require.NoError(t, os.Rename(path, path+".1"))
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+	assert.Eventually(t, func() bool {
+		return exists(path)
+	}, time.Second, 10*time.Millisecond)
+	_, err = rf.Write([]byte("after\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "before\n", readFile(t, path+".1"))
+	assert.Equal(t, "after\n", readFile(t, path))
+}
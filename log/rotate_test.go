@@ -0,0 +1,170 @@
+package log
+
+import (
+	"compress/gzip"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/madkins23/go-utils/clock"
+)
+
+// testClock returns a fake clock starting at a fixed time.
+func testClock() *clock.Fake {
+	return clock.NewFake(time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local))
+}
+
+func listDir(t *testing.T, dir string) []string {
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func readFile(t *testing.T, path string) string {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestRotatingFile_size(t *testing.T) {
+	dir := t.TempDir()
+	fake := testClock()
+	rf := &RotatingFile{Path: filepath.Join(dir, "app.log"), MaxSize: 10, Clock: fake}
+	for _, text := range []string{"12345\n", "6789\n", "abc\n", "defghijklmnop\n", "q\n"} {
+		_, err := rf.Write([]byte(text))
+		require.NoError(t, err)
+		fake.Advance(time.Second)
+	}
+	require.NoError(t, rf.Close())
+	assert.Equal(t, []string{
+		"app-20240102T030406.000.log",
+		"app-20240102T030408.000.log",
+		"app-20240102T030409.000.log",
+		"app.log",
+	}, listDir(t, dir))
+	assert.Equal(t, "12345\n", readFile(t, filepath.Join(dir, "app-20240102T030406.000.log")))
+	assert.Equal(t, "6789\nabc\n", readFile(t, filepath.Join(dir, "app-20240102T030408.000.log")))
+	assert.Equal(t, "defghijklmnop\n", readFile(t, filepath.Join(dir, "app-20240102T030409.000.log")))
+	assert.Equal(t, "q\n", readFile(t, filepath.Join(dir, "app.log")))
+
+	_, err := rf.Write([]byte("closed"))
+	assert.ErrorIs(t, err, errFileClosed)
+}
+
+func TestRotatingFile_interval(t *testing.T) {
+	dir := t.TempDir()
+	fake := testClock()
+	rf := &RotatingFile{Path: filepath.Join(dir, "app.log"), Interval: time.Hour, Clock: fake}
+	defer func() { require.NoError(t, rf.Close()) }()
+	for i := 0; i < 3; i++ {
+		_, err := rf.Write([]byte("line\n"))
+		require.NoError(t, err)
+		fake.Advance(40 * time.Minute)
+	}
+	// Writes at 03:04, 03:44, and 04:24 with rotation at 04:00.
+	assert.Equal(t, []string{"app-20240102T042405.000.log", "app.log"}, listDir(t, dir))
+	assert.Equal(t, "line\nline\n", readFile(t, filepath.Join(dir, "app-20240102T042405.000.log")))
+}
+
+func TestRotatingFile_backups(t *testing.T) {
+	dir := t.TempDir()
+	fake := testClock()
+	rf := &RotatingFile{Path: filepath.Join(dir, "app"), MaxBackups: 2, Compress: true, Clock: fake}
+	for i := 0; i < 4; i++ {
+		// Rotate twice within the same millisecond.
+		for j := 0; j < 2; j++ {
+			_, err := rf.Write([]byte("log\n"))
+			require.NoError(t, err)
+			require.NoError(t, rf.Rotate())
+		}
+		// Empty files are not rotated.
+		require.NoError(t, rf.Rotate())
+		fake.Advance(time.Minute)
+	}
+	require.NoError(t, rf.Close())
+	assert.Equal(t, []string{"app", "app-20240102T030705.000-1.gz", "app-20240102T030705.000.gz"}, listDir(t, dir))
+
+	file, err := os.Open(filepath.Join(dir, "app-20240102T030705.000.gz"))
+	require.NoError(t, err)
+	defer func() { _ = file.Close() }()
+	reader, err := gzip.NewReader(file)
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "log\n", string(data))
+}
+
+func TestRotatingFile_maxAge(t *testing.T) {
+	dir := t.TempDir()
+	fake := testClock()
+	rf := &RotatingFile{Path: filepath.Join(dir, "app.log"), MaxAge: 90 * time.Minute, Clock: fake}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app-other.log"), nil, 0666))
+	for i := 0; i < 4; i++ {
+		_, err := rf.Write([]byte("log\n"))
+		require.NoError(t, err)
+		require.NoError(t, rf.Rotate())
+		fake.Advance(time.Hour)
+	}
+	require.NoError(t, rf.Close())
+	assert.Equal(t, []string{
+		"app-20240102T050405.000.log",
+		"app-20240102T060405.000.log",
+		"app-other.log",
+		"app.log",
+	}, listDir(t, dir))
+}
+
+func TestRotatingFile_reopen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	rf := &RotatingFile{Path: path}
+	defer func() { require.NoError(t, rf.Close()) }()
+	_, err := rf.Write([]byte("before\n"))
+	require.NoError(t, err)
+
+	// Simulate logrotate.
+	require.NoError(t, os.Rename(path, path+".1"))
+	require.NoError(t, rf.Reopen())
+	_, err = rf.Write([]byte("after\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "before\n", readFile(t, path+".1"))
+	assert.Equal(t, "after\n", readFile(t, path))
+}
+
+func TestConsoleOrFile_rotation(t *testing.T) {
+	defer SetLogger(*Logger())
+	dir := t.TempDir()
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	cof := ConsoleOrFile{}
+	cof.AddFlagsToSet(flags, filepath.Join(dir, "app.log"))
+	require.NoError(t, flags.Parse([]string{
+		"-logJSON", "-logMaxSize", "1", "-logRotateEvery", "24h", "-logMaxBackups", "3",
+		"-logMaxAge", "720h", "-logCompress", "-logReopen"}))
+	require.NoError(t, cof.Setup())
+	defer cof.CloseForDefer()
+	assert.Equal(t, int64(1024*1024), cof.logFile.MaxSize)
+	assert.Equal(t, 24*time.Hour, cof.logFile.Interval)
+	assert.Equal(t, 3, cof.logFile.MaxBackups)
+	assert.Equal(t, 720*time.Hour, cof.logFile.MaxAge)
+	assert.True(t, cof.logFile.Compress)
+	assert.NotNil(t, cof.logFile.signals)
+	Info().Msg("rotating")
+	assert.Contains(t, readFile(t, filepath.Join(dir, "app.log")), `"message":"rotating"`)
+
+	bad := ConsoleOrFile{LogFile: filepath.Join(dir, "bad.log"), Rotation: Rotation{RotateEvery: "daily"}}
+	assert.ErrorContains(t, bad.Setup(), "parse rotation interval")
+	bad = ConsoleOrFile{LogFile: filepath.Join(dir, "bad.log"), Rotation: Rotation{MaxAge: "month"}}
+	assert.ErrorContains(t, bad.Setup(), "parse maximum age")
+}
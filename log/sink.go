@@ -0,0 +1,136 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// Sink types.
+const (
+	SinkStderr = "stderr"
+	SinkStdout = "stdout"
+	SinkFile   = "file"
+	SinkSocket = "socket"
+)
+
+// Sink formats.
+const (
+	FormatConsole = "console"
+	FormatJSON    = "json"
+)
+
+// Sink configures one output for log messages with its own format and minimum level.
+//
+// This struct has been configured with JSON and YAML struct tags.
+// A list of sinks may be specified in ConsoleOrFile or opened directly with OpenSinks.
+type Sink struct {
+	// Type of output: "stderr" (the default), "stdout", "file", or "socket".
+	Type string `json:"type" yaml:"type"`
+
+	// Destination is the path of the log file for file output
+	// or "network:address" for socket output (e.g. "udp:localhost:514" or "unixgram:/dev/log").
+	Destination string `json:"destination" yaml:"destination"`
+
+	// Format of output: "console" for readable output or "json".
+	// Defaults to "console" for stderr and stdout and "json" for files.
+	// Socket output is always formatted as syslog messages containing JSON.
+	Format string `json:"format" yaml:"format"`
+
+	// Minimum level of messages (e.g. "warn"), empty for all messages.
+	Level string `json:"level" yaml:"level"`
+
+	// Tag identifying the application in socket output, defaults to the program name.
+	Tag string `json:"tag" yaml:"tag"`
+
+	// Rotation of file output.
+	Rotation `yaml:",inline"`
+}
+
+var errSinkType = errors.New("unknown sink type")
+
+// Open the output for the sink.
+// Returns a writer filtered by the minimum level and an io.Closer
+// which is nil if there is nothing to close.
+func (s *Sink) Open() (zerolog.LevelWriter, io.Closer, error) {
+	level := zerolog.TraceLevel
+	if s.Level != "" {
+		var err error
+		if level, err = zerolog.ParseLevel(s.Level); err != nil {
+			return nil, nil, fmt.Errorf("parse level: %w", err)
+		}
+	}
+
+	var writer zerolog.LevelWriter
+	var closer io.Closer
+	switch strings.ToLower(s.Type) {
+	case "", SinkStderr:
+		writer = s.formatted(os.Stderr, FormatConsole, false)
+	case SinkStdout:
+		writer = s.formatted(os.Stdout, FormatConsole, false)
+	case SinkFile:
+		file, err := s.Rotation.OpenFile(s.Destination)
+		if err != nil {
+			return nil, nil, err
+		}
+		writer, closer = s.formatted(file, FormatJSON, true), file
+	case SinkSocket:
+		socket, err := newSocketWriter(s.Destination, s.Tag)
+		if err != nil {
+			return nil, nil, err
+		}
+		writer, closer = socket, socket
+	default:
+		return nil, nil, fmt.Errorf("%w '%s'", errSinkType, s.Type)
+	}
+	return &zerolog.FilteredLevelWriter{Writer: writer, Level: level}, closer, nil
+}
+
+// formatted returns a writer in the configured format or the default format.
+func (s *Sink) formatted(out io.Writer, format string, noColor bool) zerolog.LevelWriter {
+	if s.Format != "" {
+		format = strings.ToLower(s.Format)
+	}
+	if format == FormatConsole {
+		out = zerolog.ConsoleWriter{Out: out, TimeFormat: "15:04:05", NoColor: noColor}
+	}
+	return zerolog.LevelWriterAdapter{Writer: out}
+}
+
+// OpenSinks opens all the specified sinks.
+// Returns a writer to all the sinks, each filtered by its minimum level,
+// and an io.Closer for all the sinks.
+// Any sinks already opened are closed if there is an error.
+func OpenSinks(sinks ...Sink) (zerolog.LevelWriter, io.Closer, error) {
+	writers := make([]io.Writer, 0, len(sinks))
+	closers := make(multiCloser, 0, len(sinks))
+	for i := range sinks {
+		writer, closer, err := sinks[i].Open()
+		if err != nil {
+			_ = closers.Close()
+			return nil, nil, fmt.Errorf("sink %d (%s): %w", i, sinks[i].Type, err)
+		}
+		writers = append(writers, writer)
+		if closer != nil {
+			closers = append(closers, closer)
+		}
+	}
+	return zerolog.MultiLevelWriter(writers...), closers, nil
+}
+
+type multiCloser []io.Closer
+
+// Close all closers and return any errors.
+func (mc multiCloser) Close() error {
+	errs := make([]error, 0)
+	for _, closer := range mc {
+		if err := closer.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
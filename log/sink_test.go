@@ -0,0 +1,144 @@
+package log
+
+import (
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestSink_file(t *testing.T) {
+	dir := t.TempDir()
+	writer, closer, err := OpenSinks(
+		Sink{Type: SinkFile, Destination: filepath.Join(dir, "all.json")},
+		Sink{Type: SinkFile, Destination: filepath.Join(dir, "warn.log"), Format: FormatConsole, Level: "warn"},
+	)
+	require.NoError(t, err)
+	logger := zerolog.New(writer)
+	logger.Info().Msg("information")
+	logger.Warn().Msg("warning")
+	logger.Error().Msg("failure")
+	require.NoError(t, closer.Close())
+
+	assert.Equal(t, ""+
+		`{"level":"info","message":"information"}`+"\n"+
+		`{"level":"warn","message":"warning"}`+"\n"+
+		`{"level":"error","message":"failure"}`+"\n",
+		readFile(t, filepath.Join(dir, "all.json")))
+	assert.Equal(t, "<nil> WRN warning\n<nil> ERR failure\n", readFile(t, filepath.Join(dir, "warn.log")))
+}
+
+func TestSink_socket(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = listener.Close() }()
+
+	writer, closer, err := OpenSinks(Sink{
+		Type: SinkSocket, Destination: "udp:" + listener.LocalAddr().String(), Level: "warn", Tag: "tester"})
+	require.NoError(t, err)
+	defer func() { _ = closer.Close() }()
+	logger := zerolog.New(writer)
+	logger.Info().Msg("ignored")
+	logger.Error().Msg("sent")
+
+	buffer := make([]byte, 1024)
+	require.NoError(t, listener.SetReadDeadline(time.Now().Add(time.Second)))
+	n, _, err := listener.ReadFrom(buffer)
+	require.NoError(t, err)
+	message := string(buffer[:n])
+	assert.True(t, strings.HasPrefix(message, "<11>1 "), message)
+	assert.Contains(t, message, " tester ")
+	assert.True(t, strings.HasSuffix(message, ` - - {"level":"error","message":"sent"}`), message)
+
+	require.NoError(t, closer.Close())
+	logger.Error().Msg("closed")
+}
+
+func TestOpenSinks_errors(t *testing.T) {
+	dir := t.TempDir()
+	for _, test := range []struct {
+		sink     Sink
+		expected string
+	}{
+		{Sink{Type: "printer"}, "sink 1 (printer): unknown sink type 'printer'"},
+		{Sink{Level: "loud"}, "sink 1 (): parse level"},
+		{Sink{Type: SinkSocket, Destination: "localhost"}, "socket destination must be 'network:address'"},
+		{Sink{Type: SinkSocket, Destination: "bad:localhost"}, "connect to log socket"},
+		{Sink{Type: SinkFile, Destination: filepath.Join(dir, "none", "x.log")}, "open log file"},
+	} {
+		_, _, err := OpenSinks(Sink{Type: SinkFile, Destination: filepath.Join(dir, "ok.log")}, test.sink)
+		assert.ErrorContains(t, err, test.expected)
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	for level, expected := range map[zerolog.Level]int{
+		zerolog.TraceLevel: 7, zerolog.DebugLevel: 7, zerolog.InfoLevel: 6, zerolog.WarnLevel: 4,
+		zerolog.ErrorLevel: 3, zerolog.FatalLevel: 0, zerolog.PanicLevel: 2, zerolog.NoLevel: 6,
+	} {
+		assert.Equal(t, expected, severity(level), level.String())
+	}
+}
+
+func TestConsoleOrFile_sinks(t *testing.T) {
+	defer SetLogger(*Logger())
+	dir := t.TempDir()
+	cof := ConsoleOrFile{
+		LogFile: filepath.Join(dir, "app.log"),
+		AsJSON:  true,
+		Sinks: []Sink{
+			{Type: SinkFile, Destination: filepath.Join(dir, "errors.log"), Level: "error"},
+		},
+	}
+	require.NoError(t, cof.Setup())
+	Info().Msg("information")
+	Error().Msg("failure")
+	require.NoError(t, cof.Close())
+	assert.Contains(t, readFile(t, filepath.Join(dir, "app.log")), "information")
+	assert.Contains(t, readFile(t, filepath.Join(dir, "app.log")), "failure")
+	assert.NotContains(t, readFile(t, filepath.Join(dir, "errors.log")), "information")
+	assert.Contains(t, readFile(t, filepath.Join(dir, "errors.log")), "failure")
+
+	cof.Sinks = []Sink{{Type: "printer"}}
+	assert.ErrorContains(t, cof.Setup(), "open log sinks")
+	assert.Nil(t, cof.logFile)
+}
+
+func TestConsoleOrFile_unmarshal(t *testing.T) {
+	expected := ConsoleOrFile{
+		LogFile:  "app.log",
+		Rotation: Rotation{MaxSize: 10, Compress: true},
+		Sinks: []Sink{
+			{Type: SinkStderr, Level: "info"},
+			{Type: SinkFile, Destination: "app.json", Rotation: Rotation{RotateEvery: "24h"}},
+		},
+	}
+	var fromJSON ConsoleOrFile
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"logFile": "app.log", "maxSize": 10, "compress": true,
+		"sinks": [
+			{"type": "stderr", "level": "info"},
+			{"type": "file", "destination": "app.json", "rotateEvery": "24h"}
+		]}`), &fromJSON))
+	assert.Equal(t, expected, fromJSON)
+	var fromYAML ConsoleOrFile
+	require.NoError(t, yaml.Unmarshal([]byte(`
+logFile: app.log
+maxSize: 10
+compress: true
+sinks:
+  - type: stderr
+    level: info
+  - type: file
+    destination: app.json
+    rotateEvery: 24h
+`), &fromYAML))
+	assert.Equal(t, expected, fromYAML)
+}
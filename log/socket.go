@@ -0,0 +1,126 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// syslogFacility is the syslog facility for user-level messages.
+const syslogFacility = 1
+
+var errSocketAddress = errors.New("socket destination must be 'network:address'")
+
+// socketWriter writes log messages to a syslog server.
+// Each message is formatted according to RFC 5424 with the JSON log record as the message.
+type socketWriter struct {
+	lock     sync.Mutex
+	network  string
+	address  string
+	tag      string
+	hostname string
+	conn     net.Conn
+	closed   bool
+}
+
+func newSocketWriter(destination, tag string) (*socketWriter, error) {
+	network, address, found := strings.Cut(destination, ":")
+	if !found || network == "" || address == "" {
+		return nil, fmt.Errorf("%w: '%s'", errSocketAddress, destination)
+	}
+	if tag == "" {
+		tag = filepath.Base(os.Args[0])
+	}
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+	sw := &socketWriter{network: network, address: address, tag: tag, hostname: hostname}
+	if err = sw.connect(); err != nil {
+		return nil, err
+	}
+	return sw, nil
+}
+
+func (sw *socketWriter) connect() error {
+	conn, err := net.Dial(sw.network, sw.address)
+	if err != nil {
+		return fmt.Errorf("connect to log socket: %w", err)
+	}
+	sw.conn = conn
+	return nil
+}
+
+// Write a message without a level as informational.
+func (sw *socketWriter) Write(p []byte) (int, error) {
+	return sw.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel writes a message with the syslog severity matching the level.
+// The connection is reestablished if it has failed.
+func (sw *socketWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	message := fmt.Sprintf("<%d>1 %s %s %s %d - - %s",
+		syslogFacility*8+severity(level), time.Now().Format(time.RFC3339Nano),
+		sw.hostname, sw.tag, os.Getpid(), strings.TrimRight(string(p), "\n"))
+	if strings.HasPrefix(sw.network, "tcp") || sw.network == "unix" {
+		// Stream sockets need message framing.
+		message += "\n"
+	}
+	sw.lock.Lock()
+	defer sw.lock.Unlock()
+	if sw.closed {
+		return 0, errFileClosed
+	}
+	if sw.conn != nil {
+		if _, err := sw.conn.Write([]byte(message)); err == nil {
+			return len(p), nil
+		}
+		_ = sw.conn.Close()
+		sw.conn = nil
+	}
+	if err := sw.connect(); err != nil {
+		return 0, err
+	}
+	if _, err := sw.conn.Write([]byte(message)); err != nil {
+		return 0, fmt.Errorf("write to log socket: %w", err)
+	}
+	return len(p), nil
+}
+
+// Close the connection.
+func (sw *socketWriter) Close() error {
+	sw.lock.Lock()
+	defer sw.lock.Unlock()
+	sw.closed = true
+	if sw.conn == nil {
+		return nil
+	}
+	err := sw.conn.Close()
+	sw.conn = nil
+	return err
+}
+
+// severity returns the syslog severity for a level as used by zerolog.SyslogLevelWriter.
+func severity(level zerolog.Level) int {
+	switch level {
+	case zerolog.TraceLevel, zerolog.DebugLevel:
+		return 7
+	case zerolog.WarnLevel:
+		return 4
+	case zerolog.ErrorLevel:
+		return 3
+	case zerolog.FatalLevel:
+		return 0
+	case zerolog.PanicLevel:
+		return 2
+	default:
+		return 6
+	}
+}
@@ -0,0 +1,162 @@
+package msg
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+)
+
+// Code classifies an error independently of its message.
+// Codes may be mapped to HTTP status codes and process exit codes.
+type Code uint8
+
+const (
+	// CodeOK represents the absence of an error.
+	CodeOK Code = iota
+	// CodeUnknown represents an error that has not been classified.
+	CodeUnknown
+	// CodeInvalid represents invalid input such as a bad argument or malformed data.
+	CodeInvalid
+	// CodeNotFound represents a missing resource.
+	CodeNotFound
+	// CodeConflict represents a conflict with the current state of a resource.
+	CodeConflict
+	// CodeUnauthorized represents missing or invalid authentication.
+	CodeUnauthorized
+	// CodeForbidden represents an operation that is not permitted.
+	CodeForbidden
+	// CodeUnavailable represents a temporarily unavailable service or resource.
+	CodeUnavailable
+	// CodeTimeout represents an operation that ran out of time.
+	CodeTimeout
+	// CodeCanceled represents an operation that was canceled.
+	CodeCanceled
+	// CodeInternal represents a bug or unexpected condition.
+	CodeInternal
+	// CodeNotImplemented represents a feature that has not been implemented.
+	CodeNotImplemented
+	// CodeGone represents a resource or feature that is no longer available.
+	CodeGone
+)
+
+type codeInfo struct {
+	name string
+	http int
+	exit int
+}
+
+// codes maps each Code to its name, HTTP status code, and process exit code.
+// Exit codes follow the BSD sysexits.h conventions where possible.
+var codes = []codeInfo{
+	CodeOK:             {"OK", http.StatusOK, 0},
+	CodeUnknown:        {"Unknown", http.StatusInternalServerError, 1},
+	CodeInvalid:        {"Invalid", http.StatusBadRequest, 65},             // EX_DATAERR
+	CodeNotFound:       {"NotFound", http.StatusNotFound, 66},              // EX_NOINPUT
+	CodeConflict:       {"Conflict", http.StatusConflict, 65},              // EX_DATAERR
+	CodeUnauthorized:   {"Unauthorized", http.StatusUnauthorized, 77},      // EX_NOPERM
+	CodeForbidden:      {"Forbidden", http.StatusForbidden, 77},            // EX_NOPERM
+	CodeUnavailable:    {"Unavailable", http.StatusServiceUnavailable, 69}, // EX_UNAVAILABLE
+	CodeTimeout:        {"Timeout", http.StatusGatewayTimeout, 75},         // EX_TEMPFAIL
+	CodeCanceled:       {"Canceled", 499, 130},                             // Client Closed Request, SIGINT
+	CodeInternal:       {"Internal", http.StatusInternalServerError, 70},   // EX_SOFTWARE
+	CodeNotImplemented: {"NotImplemented", http.StatusNotImplemented, 70},  // EX_SOFTWARE
+	CodeGone:           {"Gone", http.StatusGone, 69},                      // EX_UNAVAILABLE
+}
+
+// info returns the table entry for the code, defaulting to CodeUnknown.
+func (c Code) info() codeInfo {
+	if int(c) < len(codes) {
+		return codes[c]
+	}
+	return codes[CodeUnknown]
+}
+
+// String returns the name of the code.
+func (c Code) String() string {
+	return c.info().name
+}
+
+// HTTPStatus returns the HTTP status code for the specified code.
+// Unrecognized codes return http.StatusInternalServerError.
+func HTTPStatus(code Code) int {
+	return code.info().http
+}
+
+// ExitCode returns the process exit code for the specified code.
+// CodeOK returns zero and unrecognized codes return one.
+func ExitCode(code Code) int {
+	return code.info().exit
+}
+
+//////////////////////////////////////////////////////////////////////////
+
+// Coder is implemented by errors that carry a Code.
+type Coder interface {
+	Code() Code
+}
+
+var _ Coder = &codedError{}
+
+// codedError attaches a Code to an arbitrary error.
+type codedError struct {
+	error
+	code Code
+}
+
+// Code implements the Coder interface.
+func (ce *codedError) Code() Code {
+	return ce.code
+}
+
+// Unwrap returns the error to which the code is attached.
+func (ce *codedError) Unwrap() error {
+	return ce.error
+}
+
+// WithCode returns an error that attaches the specified code to the specified error.
+// The result has the same message as the original error and unwraps to it.
+// Returns nil if the error is nil.
+func WithCode(err error, code Code) error {
+	if err == nil {
+		return nil
+	}
+	return &codedError{error: err, code: code}
+}
+
+// CodeOf returns the Code for the specified error.
+// The chain of wrapped errors is searched for a Coder with a code other than CodeOK or CodeUnknown.
+// The outermost such code is returned as it represents the most recent classification.
+// If no Coder is found some standard library errors are recognized:
+// context.Canceled, context.DeadlineExceeded, os.ErrNotExist, and os.ErrPermission.
+// Returns CodeOK if the error is nil and CodeUnknown if the error can't be classified.
+func CodeOf(err error) Code {
+	if err == nil {
+		return CodeOK
+	}
+	code := CodeUnknown
+	walk(err, func(err error) bool {
+		if coder, ok := err.(Coder); ok {
+			// Neither CodeOK nor CodeUnknown classifies an error.
+			if c := coder.Code(); c > CodeUnknown {
+				code = c
+				return false
+			}
+		}
+		return true
+	})
+	if code != CodeUnknown {
+		return code
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return CodeCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return CodeTimeout
+	case errors.Is(err, os.ErrNotExist):
+		return CodeNotFound
+	case errors.Is(err, os.ErrPermission):
+		return CodeForbidden
+	}
+	return CodeUnknown
+}
@@ -0,0 +1,99 @@
+package msg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func ExampleCodeOf() {
+	err := fmt.Errorf("load widget: %w", WithCode(errors.New("no such widget"), CodeNotFound))
+	code := CodeOf(err)
+	fmt.Println(code, HTTPStatus(code), ExitCode(code))
+	// Output: NotFound 404 66
+}
+
+func TestCode_String(t *testing.T) {
+	assert.Equal(t, "OK", CodeOK.String())
+	assert.Equal(t, "Invalid", CodeInvalid.String())
+	assert.Equal(t, "Gone", CodeGone.String())
+	assert.Equal(t, "Unknown", Code(200).String())
+	for c := CodeOK; c <= CodeGone; c++ {
+		assert.NotEmpty(t, c.String())
+		assert.NotZero(t, HTTPStatus(c))
+	}
+}
+
+func TestHTTPStatus(t *testing.T) {
+	assert.Equal(t, http.StatusOK, HTTPStatus(CodeOK))
+	assert.Equal(t, http.StatusInternalServerError, HTTPStatus(CodeUnknown))
+	assert.Equal(t, http.StatusBadRequest, HTTPStatus(CodeInvalid))
+	assert.Equal(t, http.StatusConflict, HTTPStatus(CodeConflict))
+	assert.Equal(t, http.StatusServiceUnavailable, HTTPStatus(CodeUnavailable))
+	assert.Equal(t, http.StatusInternalServerError, HTTPStatus(Code(200)))
+}
+
+func TestExitCode(t *testing.T) {
+	assert.Equal(t, 0, ExitCode(CodeOK))
+	assert.Equal(t, 1, ExitCode(CodeUnknown))
+	assert.Equal(t, 70, ExitCode(CodeInternal))
+	assert.Equal(t, 1, ExitCode(Code(200)))
+	for c := CodeUnknown; c <= CodeGone; c++ {
+		assert.NotZero(t, ExitCode(c))
+	}
+}
+
+func TestWithCode(t *testing.T) {
+	assert.Nil(t, WithCode(nil, CodeInvalid))
+	cause := errors.New(msg)
+	err := WithCode(cause, CodeConflict)
+	assert.Equal(t, msg, err.Error())
+	assert.ErrorIs(t, err, cause)
+	var coder Coder
+	assert.ErrorAs(t, err, &coder)
+	assert.Equal(t, CodeConflict, coder.Code())
+}
+
+func TestCodeOf(t *testing.T) {
+	assert.Equal(t, CodeOK, CodeOf(nil))
+	assert.Equal(t, CodeUnknown, CodeOf(errors.New(msg)))
+	assert.Equal(t, CodeUnknown, CodeOf(WithCode(errors.New(msg), CodeOK)))
+	assert.Equal(t, CodeInvalid, CodeOf(WithCode(errors.New(msg), CodeInvalid)))
+
+	inner := WithCode(errors.New("inner"), CodeNotFound)
+	assert.Equal(t, CodeNotFound, CodeOf(fmt.Errorf("middle: %w", inner)))
+	assert.Equal(t, CodeUnavailable, CodeOf(WithCode(fmt.Errorf("middle: %w", inner), CodeUnavailable)),
+		"outermost code wins")
+	assert.Equal(t, CodeNotFound, CodeOf(Wrap(inner, "no code")), "uncoded Error is skipped")
+	assert.Equal(t, CodeTimeout, CodeOf(errors.Join(errors.New(msg), WithCode(errors.New(msg), CodeTimeout))))
+}
+
+func TestCodeOf_standard(t *testing.T) {
+	assert.Equal(t, CodeCanceled, CodeOf(fmt.Errorf("stopped: %w", context.Canceled)))
+	assert.Equal(t, CodeTimeout, CodeOf(context.DeadlineExceeded))
+	_, err := os.Open("/no/such/file")
+	assert.Equal(t, CodeNotFound, CodeOf(err))
+	assert.Equal(t, CodeForbidden, CodeOf(os.ErrPermission))
+	assert.Equal(t, CodeInvalid, CodeOf(WithCode(err, CodeInvalid)), "explicit code wins")
+}
+
+func TestError_WithCode(t *testing.T) {
+	assert.Equal(t, CodeUnknown, New(msg).Code())
+	assert.Equal(t, CodeUnknown, CodeOf(New(msg)))
+	err := New(msg).WithCode(CodeUnauthorized)
+	assert.Equal(t, CodeUnauthorized, err.Code())
+	assert.Equal(t, CodeUnauthorized, CodeOf(fmt.Errorf("outer: %w", err)))
+}
+
+func TestErrors_Code(t *testing.T) {
+	assert.Equal(t, CodeForbidden, CodeOf(&ErrBlocked{name}))
+	assert.Equal(t, CodeGone, CodeOf(&ErrDeprecated{name}))
+	assert.Equal(t, CodeNotImplemented, CodeOf(&ErrNotImplemented{name}))
+	assert.Equal(t, CodeInternal, CodeOf(&ErrNotOverridden{name}))
+	assert.Equal(t, http.StatusNotImplemented, HTTPStatus(CodeOf(fmt.Errorf("x: %w", &ErrNotImplemented{}))))
+}
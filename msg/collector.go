@@ -0,0 +1,270 @@
+package msg
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Collector accumulates errors, optionally grouped by key.
+// Errors created by errors.Join() and MultiError objects are flattened as they are added.
+// The number of errors retained may be limited, errors beyond the limit are only counted.
+// The zero value is an empty Collector without a limit.
+// A Collector is safe for concurrent use.
+type Collector struct {
+	lock    sync.Mutex
+	limit   uint
+	count   uint
+	omitted uint
+	errs    []error
+	keyed   map[string][]error
+}
+
+// NewCollector returns a new Collector that retains at most limit errors.
+// A limit of zero means there is no limit.
+func NewCollector(limit uint) *Collector {
+	return &Collector{limit: limit}
+}
+
+// SetLimit sets the maximum number of errors retained by the Collector.
+// A limit of zero means there is no limit.
+// Errors that have already been retained are not affected.
+func (c *Collector) SetLimit(limit uint) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.limit = limit
+}
+
+// Add an error to the Collector.
+// Nil errors are ignored.
+func (c *Collector) Add(err error) {
+	c.AddKey("", err)
+}
+
+// AddKey adds an error to the Collector grouped under the specified key.
+// An empty key adds the error without grouping.
+// Nil errors are ignored.
+func (c *Collector) AddKey(key string, err error) {
+	if err == nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.add(key, err)
+}
+
+// add an error, flattening joined errors.
+// Must be called with the lock held.
+func (c *Collector) add(key string, err error) {
+	if me, ok := err.(*MultiError); ok {
+		for _, e := range me.errs {
+			c.add(key, e)
+		}
+		for _, k := range me.Keys() {
+			for _, e := range me.keyed[k] {
+				c.add(joinKey(key, k), e)
+			}
+		}
+		c.omitted += me.omitted
+		return
+	} else if errs, ok := joined(err); ok {
+		for _, e := range errs {
+			if e != nil {
+				c.add(key, e)
+			}
+		}
+		return
+	}
+
+	if c.limit > 0 && c.count >= c.limit {
+		c.omitted++
+		return
+	}
+	c.count++
+	if key == "" {
+		c.errs = append(c.errs, err)
+	} else {
+		if c.keyed == nil {
+			c.keyed = make(map[string][]error)
+		}
+		c.keyed[key] = append(c.keyed[key], err)
+	}
+}
+
+// Len returns the number of errors added to the Collector, including omitted errors.
+func (c *Collector) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return int(c.count + c.omitted)
+}
+
+// Err returns a MultiError containing the errors added so far or nil if there are none.
+// The result is a snapshot, errors added later do not affect it.
+func (c *Collector) Err() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.count+c.omitted == 0 {
+		return nil
+	}
+	result := &MultiError{omitted: c.omitted}
+	if len(c.errs) > 0 {
+		result.errs = make([]error, len(c.errs))
+		copy(result.errs, c.errs)
+	}
+	if len(c.keyed) > 0 {
+		result.keyed = make(map[string][]error, len(c.keyed))
+		for key, errs := range c.keyed {
+			result.keyed[key] = append([]error(nil), errs...)
+		}
+	}
+	return result
+}
+
+// joined returns the errors from an error created by errors.Join() or similar.
+// Errors with Unwrap() []error are only considered joined if
+// their message is the newline-separated messages of the wrapped errors,
+// otherwise they provide context of their own which would be lost by flattening.
+func joined(err error) ([]error, bool) {
+	multi, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return nil, false
+	}
+	errs := multi.Unwrap()
+	messages := make([]string, 0, len(errs))
+	for _, e := range errs {
+		if e != nil {
+			messages = append(messages, e.Error())
+		}
+	}
+	if err.Error() != strings.Join(messages, "\n") {
+		return nil, false
+	}
+	return errs, true
+}
+
+func joinKey(outer, inner string) string {
+	if outer == "" {
+		return inner
+	}
+	return outer + "." + inner
+}
+
+//////////////////////////////////////////////////////////////////////////
+
+var _ error = &MultiError{}
+
+// MultiError contains multiple errors, some of which may be grouped by key.
+// Construct MultiError objects using a Collector.
+type MultiError struct {
+	errs    []error
+	keyed   map[string][]error
+	omitted uint
+}
+
+// Errors returns the errors that are not grouped by key.
+func (me *MultiError) Errors() []error {
+	return me.errs
+}
+
+// Keys returns the keys used to group errors in sorted order.
+func (me *MultiError) Keys() []string {
+	keys := make([]string, 0, len(me.keyed))
+	for key := range me.keyed {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Keyed returns the errors grouped under the specified key.
+func (me *MultiError) Keyed(key string) []error {
+	return me.keyed[key]
+}
+
+// Omitted returns the number of errors omitted due to the Collector limit.
+func (me *MultiError) Omitted() int {
+	return int(me.omitted)
+}
+
+// Unwrap returns all retained errors for use by errors.Is() and errors.As().
+// Errors that are not grouped come first followed by grouped errors in key order.
+func (me *MultiError) Unwrap() []error {
+	result := make([]error, 0, len(me.errs))
+	result = append(result, me.errs...)
+	for _, key := range me.Keys() {
+		result = append(result, me.keyed[key]...)
+	}
+	return result
+}
+
+// Error implements the predefined error interface.
+// A single error without any omitted errors returns its own message.
+// Otherwise a multi-line message is returned with a line per error.
+// Grouped errors are prefixed with their key.
+func (me *MultiError) Error() string {
+	if len(me.errs) == 1 && len(me.keyed) == 0 && me.omitted == 0 {
+		return me.errs[0].Error()
+	}
+	var builder strings.Builder
+	count := len(me.errs) + int(me.omitted)
+	for _, errs := range me.keyed {
+		count += len(errs)
+	}
+	_, _ = fmt.Fprintf(&builder, "%d errors:", count)
+	for _, err := range me.errs {
+		writeLine(&builder, "", err)
+	}
+	for _, key := range me.Keys() {
+		for _, err := range me.keyed[key] {
+			writeLine(&builder, key+": ", err)
+		}
+	}
+	if me.omitted > 0 {
+		_, _ = fmt.Fprintf(&builder, "\n  ... and %d more", me.omitted)
+	}
+	return builder.String()
+}
+
+// writeLine writes a single error to a multi-line message.
+// Lines within the error message are indented.
+func writeLine(builder *strings.Builder, prefix string, err error) {
+	builder.WriteString("\n  ")
+	builder.WriteString(prefix)
+	builder.WriteString(strings.ReplaceAll(err.Error(), "\n", "\n    "))
+}
+
+// multiJSON is the JSON representation of a MultiError.
+type multiJSON struct {
+	Errors  []string            `json:"errors,omitempty"`
+	Keyed   map[string][]string `json:"keyed,omitempty"`
+	Omitted uint                `json:"omitted,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+// Errors are represented by their messages.
+func (me *MultiError) MarshalJSON() ([]byte, error) {
+	result := multiJSON{
+		Errors:  messages(me.errs),
+		Omitted: me.omitted,
+	}
+	if len(me.keyed) > 0 {
+		result.Keyed = make(map[string][]string, len(me.keyed))
+		for key, errs := range me.keyed {
+			result.Keyed[key] = messages(errs)
+		}
+	}
+	return json.Marshal(result)
+}
+
+func messages(errs []error) []string {
+	if len(errs) < 1 {
+		return nil
+	}
+	result := make([]string, len(errs))
+	for i, err := range errs {
+		result[i] = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,135 @@
+package msg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ExampleCollector() {
+	var collector Collector
+	collector.Add(errors.New("no configuration file"))
+	collector.AddKey("port", errors.New("not a number"))
+	collector.AddKey("host", errors.New("unknown host"))
+	collector.Add(nil)
+	fmt.Println(collector.Err())
+	// Output: 3 errors:
+	//   no configuration file
+	//   host: unknown host
+	//   port: not a number
+}
+
+func TestCollector_empty(t *testing.T) {
+	var collector Collector
+	assert.Zero(t, collector.Len())
+	assert.NoError(t, collector.Err())
+	collector.Add(nil)
+	collector.AddKey("key", nil)
+	assert.NoError(t, collector.Err())
+}
+
+func TestCollector_single(t *testing.T) {
+	collector := NewCollector(0)
+	cause := errors.New(msg)
+	collector.Add(cause)
+	err := collector.Err()
+	require.Error(t, err)
+	assert.Equal(t, msg, err.Error())
+	assert.ErrorIs(t, err, cause)
+}
+
+func TestCollector_keyed(t *testing.T) {
+	collector := NewCollector(0)
+	alpha := WithCode(errors.New("alpha"), CodeInvalid)
+	collector.AddKey("one", alpha)
+	collector.AddKey("one", errors.New("bravo"))
+	collector.AddKey("two", errors.New("charlie"))
+	err := collector.Err()
+	var multi *MultiError
+	require.ErrorAs(t, err, &multi)
+	assert.Empty(t, multi.Errors())
+	assert.Equal(t, []string{"one", "two"}, multi.Keys())
+	assert.Len(t, multi.Keyed("one"), 2)
+	assert.Len(t, multi.Keyed("two"), 1)
+	assert.Nil(t, multi.Keyed("three"))
+	assert.ErrorIs(t, err, alpha)
+	assert.Equal(t, CodeInvalid, CodeOf(err))
+	assert.Equal(t, "3 errors:\n  one: alpha\n  one: bravo\n  two: charlie", err.Error())
+}
+
+func TestCollector_limit(t *testing.T) {
+	collector := NewCollector(2)
+	for i := 0; i < 5; i++ {
+		collector.Add(errors.New(strconv.Itoa(i)))
+	}
+	assert.Equal(t, 5, collector.Len())
+	err := collector.Err()
+	var multi *MultiError
+	require.ErrorAs(t, err, &multi)
+	assert.Len(t, multi.Errors(), 2)
+	assert.Equal(t, 3, multi.Omitted())
+	assert.Equal(t, "5 errors:\n  0\n  1\n  ... and 3 more", err.Error())
+	collector.SetLimit(0)
+	collector.Add(errors.New("5"))
+	assert.Len(t, collector.Err().(*MultiError).Errors(), 3)
+}
+
+func TestCollector_flatten(t *testing.T) {
+	var collector Collector
+	collector.Add(errors.Join(errors.New("alpha"), errors.Join(errors.New("bravo"), errors.New("charlie"))))
+	wrapped := fmt.Errorf("context: %w and %w", errors.New("delta"), errors.New("echo"))
+	collector.Add(wrapped)
+	var inner Collector
+	inner.Add(errors.New("foxtrot"))
+	inner.AddKey("golf", errors.New("hotel"))
+	collector.AddKey("india", inner.Err())
+	err := collector.Err()
+	var multi *MultiError
+	require.ErrorAs(t, err, &multi)
+	require.Len(t, multi.Errors(), 4, "joined errors are flattened, wrapped errors are not")
+	assert.Equal(t, "alpha", multi.Errors()[0].Error())
+	assert.Equal(t, "charlie", multi.Errors()[2].Error())
+	assert.Equal(t, wrapped, multi.Errors()[3])
+	assert.Equal(t, []string{"india", "india.golf"}, multi.Keys())
+}
+
+func TestCollector_multiLine(t *testing.T) {
+	var collector Collector
+	collector.Add(errors.New("alpha"))
+	collector.Add(fmt.Errorf("bravo:\n%w", errors.New("charlie")))
+	assert.Equal(t, "2 errors:\n  alpha\n  bravo:\n    charlie", collector.Err().Error())
+}
+
+func TestCollector_concurrent(t *testing.T) {
+	var collector Collector
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				collector.AddKey(strconv.Itoa(i), errors.New(strconv.Itoa(j)))
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, 1000, collector.Len())
+	assert.Len(t, collector.Err().(*MultiError).Unwrap(), 1000)
+}
+
+func TestMultiError_MarshalJSON(t *testing.T) {
+	collector := NewCollector(3)
+	collector.Add(errors.New("alpha"))
+	collector.AddKey("key", errors.New("bravo"))
+	collector.AddKey("key", errors.New("charlie"))
+	collector.Add(errors.New("delta"))
+	bytes, err := json.Marshal(collector.Err())
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"errors":["alpha"],"keyed":{"key":["bravo","charlie"]},"omitted":1}`, string(bytes))
+}
@@ -0,0 +1,238 @@
+package msg
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+)
+
+var _ error = &Error{}
+
+// Field is a key/value pair attached to an Error.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Error is a general-purpose error object that captures a stack trace when created.
+// Key/value fields may be attached and a cause may be wrapped.
+// Formatting an Error with %+v shows the fields, the stack trace and any cause.
+//
+// Construct Error objects using New(), Newf(), Wrap(), or Wrapf()
+// and attach fields using With() and a Code using WithCode().
+type Error struct {
+	message string
+	cause   error
+	code    Code
+	fields  []Field
+	stack   []uintptr
+	full    bool // message includes the message of the cause (see ErrorTree.Full)
+}
+
+// New returns a new Error with the specified message.
+func New(message string) *Error {
+	return newError(message, nil)
+}
+
+// Newf returns a new Error with a formatted message.
+func Newf(format string, args ...interface{}) *Error {
+	return newError(fmt.Sprintf(format, args...), nil)
+}
+
+// Wrap returns a new Error with the specified message wrapping the specified cause.
+func Wrap(cause error, message string) *Error {
+	return newError(message, cause)
+}
+
+// Wrapf returns a new Error with a formatted message wrapping the specified cause.
+func Wrapf(cause error, format string, args ...interface{}) *Error {
+	return newError(fmt.Sprintf(format, args...), cause)
+}
+
+const stackDepth = 32
+
+func newError(message string, cause error) *Error {
+	pcs := make([]uintptr, stackDepth)
+	// Skip runtime.Callers, newError, and the exported constructor.
+	n := runtime.Callers(3, pcs)
+	return &Error{
+		message: message,
+		cause:   cause,
+		stack:   pcs[:n],
+	}
+}
+
+// With attaches a key/value field to the Error.
+// Returns the Error so that calls may be chained.
+func (e *Error) With(key string, value interface{}) *Error {
+	e.fields = append(e.fields, Field{Key: key, Value: value})
+	return e
+}
+
+// WithCode attaches a Code to the Error.
+// Returns the Error so that calls may be chained.
+func (e *Error) WithCode(code Code) *Error {
+	e.code = code
+	return e
+}
+
+// Code implements the Coder interface.
+// Returns CodeUnknown if no code has been attached,
+// in which case CodeOf() will continue searching the chain of wrapped errors.
+func (e *Error) Code() Code {
+	if e.code == CodeOK {
+		return CodeUnknown
+	}
+	return e.code
+}
+
+// Error implements the predefined error interface.
+// The message is followed by the message of any cause.
+func (e *Error) Error() string {
+	if e.cause == nil || e.full {
+		return e.message
+	} else if e.message == "" {
+		return e.cause.Error()
+	} else {
+		return e.message + ": " + e.cause.Error()
+	}
+}
+
+// Unwrap returns the cause of the Error, if any.
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Message returns the message for this Error without any cause.
+func (e *Error) Message() string {
+	return e.message
+}
+
+// Fields returns the fields attached to this Error in the order they were attached.
+// Fields attached to any cause are not included, see the Fields() function.
+func (e *Error) Fields() []Field {
+	return e.fields
+}
+
+// StackTrace returns the stack trace captured when the Error was created.
+func (e *Error) StackTrace() []runtime.Frame {
+	return frames(e.stack)
+}
+
+// Format implements the fmt.Formatter interface.
+// The %s and %v verbs print the error message.
+// The %+v verb adds the fields, stack trace, and formatted cause.
+func (e *Error) Format(state fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if state.Flag('+') {
+			_, _ = io.WriteString(state, e.message)
+			for _, field := range e.fields {
+				_, _ = fmt.Fprintf(state, " %s=%v", field.Key, field.Value)
+			}
+			writeFrames(state, e.StackTrace())
+			if e.cause != nil {
+				_, _ = fmt.Fprintf(state, "\ncaused by: %+v", e.cause)
+			}
+			return
+		}
+		fallthrough
+	case 's':
+		_, _ = io.WriteString(state, e.Error())
+	case 'q':
+		_, _ = fmt.Fprintf(state, "%q", e.Error())
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////
+
+// Fields returns all fields attached to Error objects along the chain of wrapped errors.
+// Both Unwrap() error and Unwrap() []error are followed.
+// If the same key occurs more than once the value closest to the top of the chain is used.
+// The result may be passed to zerolog.Event.Fields() to log the fields.
+// Returns nil if there are no fields.
+func Fields(err error) map[string]interface{} {
+	var result map[string]interface{}
+	walk(err, func(err error) bool {
+		if e, ok := err.(*Error); ok {
+			for _, field := range e.fields {
+				if result == nil {
+					result = make(map[string]interface{})
+				}
+				if _, found := result[field.Key]; !found {
+					result[field.Key] = field.Value
+				}
+			}
+		}
+		return true
+	})
+	return result
+}
+
+// StackTrace returns the stack trace from the deepest error with a stack trace
+// along the chain of wrapped errors, such as an Error or a PanicError.
+// This is generally the stack trace closest to the origin of the error.
+// Returns nil if there is no such error in the chain.
+func StackTrace(err error) []runtime.Frame {
+	var deepest stackTracer
+	walk(err, func(err error) bool {
+		if st, ok := err.(stackTracer); ok {
+			deepest = st
+		}
+		return true
+	})
+	if deepest == nil {
+		return nil
+	}
+	return deepest.StackTrace()
+}
+
+type stackTracer interface {
+	StackTrace() []runtime.Frame
+}
+
+// frames converts program counters into stack frames.
+func frames(stack []uintptr) []runtime.Frame {
+	if len(stack) < 1 {
+		return nil
+	}
+	result := make([]runtime.Frame, 0, len(stack))
+	frames := runtime.CallersFrames(stack)
+	for {
+		frame, more := frames.Next()
+		result = append(result, frame)
+		if !more {
+			break
+		}
+	}
+	return result
+}
+
+// writeFrames writes stack frames for the %+v format verb.
+func writeFrames(w io.Writer, frames []runtime.Frame) {
+	for _, frame := range frames {
+		_, _ = fmt.Fprintf(w, "\n    %s\n        %s:%d", frame.Function, frame.File, frame.Line)
+	}
+}
+
+// walk the chain of wrapped errors depth first, calling the specified function for each.
+// Both Unwrap() error and Unwrap() []error are followed.
+// Walking stops if the function returns false.
+func walk(err error, fn func(err error) bool) bool {
+	if err == nil {
+		return true
+	} else if !fn(err) {
+		return false
+	}
+	switch x := err.(type) {
+	case interface{ Unwrap() error }:
+		return walk(x.Unwrap(), fn)
+	case interface{ Unwrap() []error }:
+		for _, e := range x.Unwrap() {
+			if !walk(e, fn) {
+				return false
+			}
+		}
+	}
+	return true
+}
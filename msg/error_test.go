@@ -0,0 +1,111 @@
+package msg
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ExampleNew() {
+	err := New("no such widget").With("widget", "sprocket").With("count", 3)
+	fmt.Println(err)
+	fmt.Println(Fields(err))
+	// Output: no such widget
+	// map[count:3 widget:sprocket]
+}
+
+func ExampleWrap() {
+	cause := errors.New("file not found")
+	err := Wrap(cause, "load configuration").With("path", "/etc/app.cfg")
+	fmt.Println(err)
+	fmt.Println(errors.Is(err, cause))
+	// Output: load configuration: file not found
+	// true
+}
+
+func TestError(t *testing.T) {
+	err := New(msg)
+	require.NotNil(t, err)
+	assert.Equal(t, msg, err.Error())
+	assert.Equal(t, msg, err.Message())
+	assert.Nil(t, err.Unwrap())
+	assert.Nil(t, err.Fields())
+	assert.Equal(t, "eMsg 17", Newf("%s %d", msg, 17).Error())
+
+	cause := errors.New("cause")
+	wrapped := Wrapf(cause, "%s %d", msg, 23)
+	assert.Equal(t, "eMsg 23: cause", wrapped.Error())
+	assert.Equal(t, "eMsg 23", wrapped.Message())
+	assert.Equal(t, cause, wrapped.Unwrap())
+	assert.ErrorIs(t, wrapped, cause)
+	assert.Equal(t, "cause", Wrap(cause, "").Error())
+
+	var target *Error
+	require.ErrorAs(t, fmt.Errorf("outer: %w", wrapped), &target)
+	assert.Equal(t, wrapped, target)
+}
+
+func TestError_With(t *testing.T) {
+	err := New(msg).With("alpha", 1).With("bravo", "two")
+	assert.Equal(t, []Field{{"alpha", 1}, {"bravo", "two"}}, err.Fields())
+}
+
+func TestError_StackTrace(t *testing.T) {
+	err := New(msg)
+	frames := err.StackTrace()
+	require.NotEmpty(t, frames)
+	assert.True(t, strings.HasSuffix(frames[0].Function, "msg.TestError_StackTrace"), frames[0].Function)
+	assert.True(t, strings.HasSuffix(frames[0].File, "error_test.go"), frames[0].File)
+	assert.Nil(t, (&Error{}).StackTrace())
+}
+
+func TestError_Format(t *testing.T) {
+	cause := New("cause").With("inner", true)
+	err := Wrap(cause, msg).With("name", name)
+	assert.Equal(t, "eMsg: cause", fmt.Sprintf("%s", err))
+	assert.Equal(t, "eMsg: cause", fmt.Sprintf("%v", err))
+	assert.Equal(t, `"eMsg: cause"`, fmt.Sprintf("%q", err))
+	verbose := fmt.Sprintf("%+v", err)
+	assert.True(t, strings.HasPrefix(verbose, "eMsg name=name\n"), verbose)
+	assert.Contains(t, verbose, "msg.TestError_Format")
+	assert.Contains(t, verbose, "error_test.go:")
+	assert.Contains(t, verbose, "\ncaused by: cause inner=true\n")
+}
+
+func TestFields(t *testing.T) {
+	assert.Nil(t, Fields(nil))
+	assert.Nil(t, Fields(errors.New("plain")))
+	inner := New("inner").With("alpha", 1).With("bravo", 2)
+	middle := fmt.Errorf("middle: %w", inner)
+	outer := Wrap(middle, "outer").With("bravo", "outer").With("charlie", 3)
+	other := New("other").With("delta", 4)
+	joined := errors.Join(outer, other)
+	assert.Equal(t, map[string]interface{}{
+		"alpha":   1,
+		"bravo":   "outer",
+		"charlie": 3,
+		"delta":   4,
+	}, Fields(joined))
+}
+
+func TestFields_zerolog(t *testing.T) {
+	var builder strings.Builder
+	logger := zerolog.New(&builder)
+	err := New("failure").With("alpha", "one").With("bravo", 2)
+	logger.Error().Err(err).Fields(Fields(err)).Msg("oops")
+	assert.Equal(t,
+		`{"level":"error","error":"failure","alpha":"one","bravo":2,"message":"oops"}`+"\n",
+		builder.String())
+}
+
+func TestStackTrace(t *testing.T) {
+	assert.Nil(t, StackTrace(errors.New("plain")))
+	inner := New("inner")
+	outer := Wrap(fmt.Errorf("middle: %w", inner), "outer")
+	assert.Equal(t, inner.StackTrace(), StackTrace(outer))
+}
@@ -0,0 +1,268 @@
+package msg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// ErrorTree is a serializable representation of a chain of wrapped errors.
+type ErrorTree struct {
+	// Message is the message contributed by this error, not including any causes.
+	// Messages of wrappers that don't follow the "message: cause" convention are kept in full.
+	Message string `json:"message,omitempty"`
+	// Full is true if Message is kept in full, including the messages of any causes.
+	Full bool `json:"full,omitempty"`
+	// Type is the Go type of the error.
+	Type string `json:"type,omitempty"`
+	// Code is the name of any Code attached to the error.
+	Code string `json:"code,omitempty"`
+	// Fields attached to the error.
+	Fields map[string]interface{} `json:"fields,omitempty"`
+	// Causes are the errors wrapped by the error.
+	// Both Unwrap() error and Unwrap() []error are followed.
+	Causes []*ErrorTree `json:"causes,omitempty"`
+}
+
+// Tree returns an ErrorTree representing the specified error and its causes.
+// Errors created by WithCode() are merged into the error they wrap.
+// Returns nil if the error is nil.
+func Tree(err error) *ErrorTree {
+	if err == nil {
+		return nil
+	}
+	if ce, ok := err.(*codedError); ok {
+		tree := Tree(ce.error)
+		if ce.code > CodeUnknown {
+			tree.Code = ce.code.String()
+		}
+		return tree
+	}
+
+	tree := &ErrorTree{
+		Message: err.Error(),
+		Type:    fmt.Sprintf("%T", err),
+	}
+	if coder, ok := err.(Coder); ok && coder.Code() > CodeUnknown {
+		tree.Code = coder.Code().String()
+	}
+	switch x := err.(type) {
+	case *Error:
+		tree.Message = x.message
+		tree.Full = x.full
+		if len(x.fields) > 0 {
+			tree.Fields = make(map[string]interface{}, len(x.fields))
+			for _, field := range x.fields {
+				tree.Fields[field.Key] = field.Value
+			}
+		}
+		if x.cause != nil {
+			tree.Causes = []*ErrorTree{Tree(x.cause)}
+		}
+	case interface{ Unwrap() error }:
+		if cause := x.Unwrap(); cause != nil {
+			tree.Message, tree.Full = ownMessage(tree.Message, cause.Error())
+			tree.Causes = []*ErrorTree{Tree(cause)}
+		}
+	case interface{ Unwrap() []error }:
+		if _, ok := joined(err); ok {
+			tree.Message = ""
+		} else {
+			tree.Full = true
+		}
+		for _, cause := range x.Unwrap() {
+			if cause != nil {
+				tree.Causes = append(tree.Causes, Tree(cause))
+			}
+		}
+	}
+	return tree
+}
+
+// ownMessage removes the message of a cause from the message of the error wrapping it.
+// Returns the full message and true if the message doesn't end with the message of the cause.
+func ownMessage(message, cause string) (string, bool) {
+	if message == cause {
+		return "", false
+	} else if own := strings.TrimSuffix(message, ": "+cause); own != message {
+		return own, false
+	}
+	return message, true
+}
+
+// Marshal returns JSON for an ErrorTree representing the specified error and its causes.
+func Marshal(err error) ([]byte, error) {
+	return json.Marshal(Tree(err))
+}
+
+//////////////////////////////////////////////////////////////////////////
+
+// DecodeFn reconstructs an error of a specific type from an ErrorTree.
+// The cause is the reconstructed cause of the error, if any.
+type DecodeFn func(tree *ErrorTree, cause error) error
+
+var (
+	decodersLock sync.RWMutex
+	decoders     = map[string]DecodeFn{
+		fmt.Sprintf("%T", ConstError("")): func(tree *ErrorTree, _ error) error {
+			return ConstError(tree.Message)
+		},
+		fmt.Sprintf("%T", &ErrBlocked{}):        decodeNamed[blockedKind],
+		fmt.Sprintf("%T", &ErrDeprecated{}):     decodeNamed[deprecatedKind],
+		fmt.Sprintf("%T", &ErrNotImplemented{}): decodeNamed[notImplementedKind],
+		fmt.Sprintf("%T", &ErrNotOverridden{}):  decodeNamed[notOverriddenKind],
+	}
+)
+
+// RegisterDecoder registers a function to reconstruct errors of the specified type.
+// The type name is as reported by the %T format verb, e.g. "*mypkg.MyError".
+func RegisterDecoder(typeName string, fn DecodeFn) {
+	decodersLock.Lock()
+	defer decodersLock.Unlock()
+	decoders[typeName] = fn
+}
+
+// decodeNamed reconstructs a NamedError from its message.
+func decodeNamed[K Kind](tree *ErrorTree, _ error) error {
+	var kind K
+	if tree.Message == kind.Generic() {
+		return &NamedError[K]{}
+	}
+	return &NamedError[K]{Name: strings.TrimSuffix(tree.Message, kind.Named())}
+}
+
+// Unmarshal reconstructs an error from JSON created by Marshal.
+// Errors of registered types (including ConstError and the named errors in this package)
+// are reconstructed as that type, all other errors are reconstructed as Error objects
+// with the original message, code, fields, and causes but no stack trace.
+// Multiple causes are reconstructed using errors.Join().
+// Codes are preserved by wrapping errors from registered types with WithCode() if necessary.
+// The reconstructed error has the same message as the original error
+// unless a registered type reconstructs a different message.
+// Returns a nil error if the JSON represents a nil error.
+func Unmarshal(data []byte) (error, error) {
+	var tree *ErrorTree
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("unmarshal error tree: %w", err)
+	}
+	return tree.Decode(), nil
+}
+
+// Decode reconstructs an error from the ErrorTree.
+// See Unmarshal for details.
+func (t *ErrorTree) Decode() error {
+	if t == nil {
+		return nil
+	}
+
+	var cause error
+	if len(t.Causes) == 1 {
+		cause = t.Causes[0].Decode()
+	} else if len(t.Causes) > 1 {
+		causes := make([]error, len(t.Causes))
+		for i, c := range t.Causes {
+			causes[i] = c.Decode()
+		}
+		cause = errors.Join(causes...)
+	}
+
+	decodersLock.RLock()
+	decode, found := decoders[t.Type]
+	decodersLock.RUnlock()
+	if found {
+		decoded := decode(t, cause)
+		if code := parseCode(t.Code); code > CodeUnknown {
+			if coder, ok := decoded.(Coder); !ok || coder.Code() != code {
+				decoded = WithCode(decoded, code)
+			}
+		}
+		return decoded
+	} else if t.Message == "" && t.Code == "" && len(t.Fields) < 1 && len(t.Causes) > 1 {
+		// Joined errors.
+		return cause
+	}
+
+	result := &Error{
+		message: t.Message,
+		cause:   cause,
+		code:    parseCode(t.Code),
+		full:    t.Full,
+	}
+	if len(t.Fields) > 0 {
+		keys := make([]string, 0, len(t.Fields))
+		for key := range t.Fields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			result.fields = append(result.fields, Field{Key: key, Value: t.Fields[key]})
+		}
+	}
+	return result
+}
+
+// parseCode returns the Code with the specified name or CodeUnknown.
+func parseCode(name string) Code {
+	for i, info := range codes {
+		if info.name == name {
+			return Code(i)
+		}
+	}
+	return CodeUnknown
+}
+
+//////////////////////////////////////////////////////////////////////////
+
+var _ zerolog.LogObjectMarshaler = &ErrorTree{}
+
+// MarshalZerologObject implements the zerolog.LogObjectMarshaler interface.
+func (t *ErrorTree) MarshalZerologObject(event *zerolog.Event) {
+	if t.Message != "" {
+		event.Str("message", t.Message)
+	}
+	if t.Full {
+		event.Bool("full", true)
+	}
+	if t.Type != "" {
+		event.Str("type", t.Type)
+	}
+	if t.Code != "" {
+		event.Str("code", t.Code)
+	}
+	if len(t.Fields) > 0 {
+		event.Dict("fields", zerolog.Dict().Fields(t.Fields))
+	}
+	if len(t.Causes) > 0 {
+		causes := zerolog.Arr()
+		for _, cause := range t.Causes {
+			causes.Object(cause)
+		}
+		event.Array("causes", causes)
+	}
+}
+
+// ZerologMarshalFunc may be used as zerolog.ErrorMarshalFunc to log errors as ErrorTree objects.
+// Errors without any causes, code, or fields are logged as simple strings.
+func ZerologMarshalFunc(err error) interface{} {
+	tree := Tree(err)
+	if tree == nil {
+		return nil
+	} else if len(tree.Causes) < 1 && tree.Code == "" && len(tree.Fields) < 1 {
+		return err
+	}
+	return tree
+}
+
+// UseZerologMarshalFunc sets zerolog.ErrorMarshalFunc to ZerologMarshalFunc
+// so that errors logged via zerolog.Event.Err() show the full chain of wrapped errors.
+// Returns the previous zerolog.ErrorMarshalFunc so that it may be restored.
+func UseZerologMarshalFunc() func(err error) interface{} {
+	previous := zerolog.ErrorMarshalFunc
+	zerolog.ErrorMarshalFunc = ZerologMarshalFunc
+	return previous
+}
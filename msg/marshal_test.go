@@ -0,0 +1,178 @@
+package msg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ExampleMarshal() {
+	cause := WithCode(errors.New("no such widget"), CodeNotFound)
+	err := Wrap(fmt.Errorf("lookup: %w", cause), "load widget").With("id", 17)
+	data, _ := Marshal(err)
+	fmt.Println(string(data))
+	// Output: {"message":"load widget","type":"*msg.Error","fields":{"id":17},"causes":[{"message":"lookup","type":"*fmt.wrapError","causes":[{"message":"no such widget","type":"*errors.errorString","code":"NotFound"}]}]}
+}
+
+func TestTree(t *testing.T) {
+	assert.Nil(t, Tree(nil))
+	err := errors.Join(
+		New("alpha").WithCode(CodeInvalid),
+		fmt.Errorf("bravo %w charlie", context.Canceled),
+		&ErrBlocked{Name: name})
+	tree := Tree(err)
+	require.NotNil(t, tree)
+	assert.Empty(t, tree.Message, "joined errors have no message of their own")
+	require.Len(t, tree.Causes, 3)
+	assert.Equal(t, "alpha", tree.Causes[0].Message)
+	assert.Equal(t, "Invalid", tree.Causes[0].Code)
+	assert.Equal(t, "bravo context canceled charlie", tree.Causes[1].Message)
+	require.Len(t, tree.Causes[1].Causes, 1)
+	assert.Equal(t, "context canceled", tree.Causes[1].Causes[0].Message)
+	assert.Equal(t, name+strBlockedNamed, tree.Causes[2].Message)
+	assert.Equal(t, "Forbidden", tree.Causes[2].Code)
+
+	multi := Tree(fmt.Errorf("%w and %w", errors.New("alpha"), errors.New("bravo")))
+	assert.Equal(t, "alpha and bravo", multi.Message)
+	assert.Len(t, multi.Causes, 2)
+}
+
+func TestUnmarshal(t *testing.T) {
+	original := Wrap(
+		errors.Join(
+			WithCode(fmt.Errorf("bravo: %w", ConstError("charlie")), CodeConflict),
+			&ErrNotImplemented{Name: "delta"},
+			&ErrDeprecated{}),
+		"alpha").With("one", 1).With("two", "2")
+	data, err := Marshal(original)
+	require.NoError(t, err)
+	decoded, err := Unmarshal(data)
+	require.NoError(t, err)
+	require.Error(t, decoded)
+	assert.Equal(t, original.Error(), decoded.Error())
+	assert.Equal(t, CodeConflict, CodeOf(decoded))
+	assert.Equal(t, map[string]interface{}{"one": float64(1), "two": "2"}, Fields(decoded))
+	assert.ErrorIs(t, decoded, ConstError("charlie"))
+	assert.ErrorIs(t, decoded, &ErrNotImplemented{Name: "delta"})
+	assert.ErrorIs(t, decoded, &ErrDeprecated{})
+	assert.False(t, errors.Is(decoded, &ErrBlocked{}))
+	assert.Nil(t, StackTrace(decoded))
+
+	redo, err := Marshal(decoded)
+	require.NoError(t, err)
+	again, err := Unmarshal(redo)
+	require.NoError(t, err)
+	assert.Equal(t, original.Error(), again.Error())
+
+	decoded, err = Unmarshal([]byte("null"))
+	assert.NoError(t, err)
+	assert.Nil(t, decoded)
+	_, err = Unmarshal([]byte("{"))
+	assert.Error(t, err)
+}
+
+func TestUnmarshal_code(t *testing.T) {
+	for _, original := range []error{
+		WithCode(ConstError("boom"), CodeNotFound),
+		WithCode(&ErrNotImplemented{Name: "thing"}, CodeInvalid),
+		fmt.Errorf("wrapped: %w", WithCode(ConstError("boom"), CodeConflict)),
+	} {
+		data, err := Marshal(original)
+		require.NoError(t, err)
+		decoded, err := Unmarshal(data)
+		require.NoError(t, err)
+		assert.Equal(t, original.Error(), decoded.Error())
+		assert.Equal(t, CodeOf(original), CodeOf(decoded), original.Error())
+	}
+}
+
+func TestUnmarshal_fullMessage(t *testing.T) {
+	x := ConstError("x")
+	for _, original := range []error{
+		fmt.Errorf("%w happened", x),
+		fmt.Errorf("before %w after", x),
+		fmt.Errorf("%w and %w", x, ConstError("y")),
+		fmt.Errorf("prefix: %w", x),
+		fmt.Errorf("%w", x),
+	} {
+		data, err := Marshal(original)
+		require.NoError(t, err)
+		decoded, err := Unmarshal(data)
+		require.NoError(t, err)
+		assert.Equal(t, original.Error(), decoded.Error())
+		assert.ErrorIs(t, decoded, x)
+	}
+	assert.True(t, Tree(fmt.Errorf("%w happened", x)).Full)
+	assert.False(t, Tree(fmt.Errorf("prefix: %w", x)).Full)
+}
+
+type fullWrapper struct{ cause error }
+
+func (fw *fullWrapper) Error() string { return "custom (" + fw.cause.Error() + ")" }
+func (fw *fullWrapper) Unwrap() error { return fw.cause }
+
+func TestUnmarshal_fullMessageTwice(t *testing.T) {
+	original := &fullWrapper{cause: ConstError("inner")}
+	data, err := Marshal(original)
+	require.NoError(t, err)
+	decoded, err := Unmarshal(data)
+	require.NoError(t, err)
+	assert.Equal(t, "custom (inner)", decoded.Error())
+	data, err = Marshal(decoded)
+	require.NoError(t, err)
+	decoded, err = Unmarshal(data)
+	require.NoError(t, err)
+	assert.Equal(t, "custom (inner)", decoded.Error())
+	assert.ErrorIs(t, decoded, ConstError("inner"))
+}
+
+type customError struct{ value string }
+
+func (ce *customError) Error() string { return "custom " + ce.value }
+
+func TestRegisterDecoder(t *testing.T) {
+	typeName := fmt.Sprintf("%T", &customError{})
+	RegisterDecoder(typeName, func(tree *ErrorTree, _ error) error {
+		return &customError{value: tree.Message[len("custom "):]}
+	})
+	data, err := Marshal(fmt.Errorf("wrapped: %w", &customError{value: "value"}))
+	require.NoError(t, err)
+	decoded, err := Unmarshal(data)
+	require.NoError(t, err)
+	var custom *customError
+	require.ErrorAs(t, decoded, &custom)
+	assert.Equal(t, "value", custom.value)
+}
+
+func TestZerologMarshalFunc(t *testing.T) {
+	previous := UseZerologMarshalFunc()
+	defer func() {
+		zerolog.ErrorMarshalFunc = previous
+	}()
+	var buffer bytes.Buffer
+	logger := zerolog.New(&buffer)
+
+	logger.Error().Err(errors.New("simple")).Send()
+	assert.Equal(t, `{"level":"error","error":"simple"}`+"\n", buffer.String())
+
+	buffer.Reset()
+	logger.Error().Err(Wrap(ConstError("cause"), "failure").WithCode(CodeTimeout).With("key", "value")).Send()
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &record))
+	assert.Equal(t, map[string]interface{}{
+		"message": "failure",
+		"type":    "*msg.Error",
+		"code":    "Timeout",
+		"fields":  map[string]interface{}{"key": "value"},
+		"causes": []interface{}{
+			map[string]interface{}{"message": "cause", "type": "msg.ConstError"},
+		},
+	}, record["error"])
+}
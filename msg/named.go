@@ -0,0 +1,155 @@
+package msg
+
+import "errors"
+
+// Kind defines the messages for a category of NamedError.
+// Kinds are normally empty struct types that exist only to provide these methods
+// (see Category for declaring a category of errors in one line):
+//
+//	type lockedKind struct{}
+//
+//	func (lockedKind) Generic() string { return "locked" }
+//	func (lockedKind) Named() string   { return " is locked" }
+//
+//	type ErrLocked = msg.NamedError[lockedKind]
+//
+// If the Kind also implements Coder the NamedError will return its code.
+type Kind interface {
+	// Generic returns the error message used when there is no name.
+	Generic() string
+	// Named returns the suffix appended to the name to form the error message.
+	Named() string
+}
+
+// NamedError is a generic error with an optional name for a category defined by a Kind.
+// A generic NamedError (one with an empty Name) matches any NamedError of the same Kind
+// via errors.Is(), a NamedError with a Name only matches errors of the same Kind with the same Name.
+type NamedError[K Kind] struct {
+	// Optional name of the thing in error, usually a function or method.
+	Name string
+}
+
+// Error implements the predefined error interface.
+func (ne *NamedError[K]) Error() string {
+	var kind K
+	if ne.Name == "" {
+		return kind.Generic()
+	} else {
+		return ne.Name + kind.Named()
+	}
+}
+
+// Is determines if the error is or contains the target error.
+func (ne *NamedError[K]) Is(target error) bool {
+	var other *NamedError[K]
+	if !errors.As(target, &other) {
+		return false
+	} else if other.Name != "" {
+		return other.Name == ne.Name
+	} else {
+		return true
+	}
+}
+
+// Code implements the Coder interface.
+// Returns the code from the Kind if it implements Coder, otherwise CodeUnknown.
+func (ne *NamedError[K]) Code() Code {
+	var kind K
+	if coder, ok := interface{}(kind).(Coder); ok {
+		return coder.Code()
+	}
+	return CodeUnknown
+}
+
+//////////////////////////////////////////////////////////////////////////
+
+var (
+	_ error = &Category{}
+	_ error = &CategoryError{}
+)
+
+// Category is a category of errors with an optional name, declared in one line:
+//
+//	var ErrLocked = msg.NewCategory("locked", " is locked")
+//
+// The Category itself is the generic error of the category and Named() returns errors
+// for specific names that are matched by errors.Is() as for NamedError:
+//
+//	err := ErrLocked.Named("database")     // "database is locked"
+//	errors.Is(err, ErrLocked)              // true
+//	errors.Is(err, ErrLocked.Named("db"))  // false
+//
+// Unlike NamedError, errors of a Category are not distinct types,
+// so they are matched with errors.Is() rather than errors.As()
+// and are reconstructed by Unmarshal as Error objects.
+type Category struct {
+	generic string
+	named   string
+	code    Code
+}
+
+// NewCategory returns a new Category with the generic error message used when there is no name
+// and the suffix appended to the name to form the error message for a specific name.
+func NewCategory(generic, named string) *Category {
+	return &Category{generic: generic, named: named, code: CodeUnknown}
+}
+
+// WithCode attaches a Code to the Category and all errors created from it.
+// Returns the Category so that it can be used in the declaration.
+func (c *Category) WithCode(code Code) *Category {
+	c.code = code
+	return c
+}
+
+// Named returns an error of the Category for the specified name.
+// An empty name returns the generic error message.
+func (c *Category) Named(name string) *CategoryError {
+	return &CategoryError{Category: c, Name: name}
+}
+
+// Error implements the predefined error interface.
+func (c *Category) Error() string {
+	return c.generic
+}
+
+// Code implements the Coder interface.
+func (c *Category) Code() Code {
+	return c.code
+}
+
+// CategoryError is an error of a Category with an optional name.
+// Construct CategoryError objects using Category.Named().
+type CategoryError struct {
+	// Category of the error.
+	Category *Category
+	// Optional name of the thing in error, usually a function or method.
+	Name string
+}
+
+// Error implements the predefined error interface.
+func (ce *CategoryError) Error() string {
+	if ce.Name == "" {
+		return ce.Category.generic
+	}
+	return ce.Name + ce.Category.named
+}
+
+// Is determines if the error is or contains the target error.
+// The target matches if it is the Category of the error or an error of the same Category
+// with the same name or no name.
+func (ce *CategoryError) Is(target error) bool {
+	if category, ok := target.(*Category); ok {
+		return category == ce.Category
+	}
+	var other *CategoryError
+	if !errors.As(target, &other) || other.Category != ce.Category {
+		return false
+	}
+	return other.Name == "" || other.Name == ce.Name
+}
+
+// Code implements the Coder interface.
+// Returns the code attached to the Category, if any.
+func (ce *CategoryError) Code() Code {
+	return ce.Category.code
+}
@@ -0,0 +1,68 @@
+package msg
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type lockedKind struct{}
+
+func (lockedKind) Generic() string { return "locked" }
+func (lockedKind) Named() string   { return " is locked" }
+
+type errLocked = NamedError[lockedKind]
+
+func ExampleNamedError() {
+	err := fmt.Errorf("update: %w", &errLocked{Name: "database"})
+	fmt.Println(err)
+	fmt.Println(errors.Is(err, &errLocked{}))
+	fmt.Println(errors.Is(err, &errLocked{Name: "cache"}))
+	// Output: update: database is locked
+	// true
+	// false
+}
+
+func TestNamedError(t *testing.T) {
+	assert.Equal(t, "locked", (&errLocked{}).Error())
+	assert.Equal(t, name+" is locked", (&errLocked{name}).Error())
+	assert.ErrorIs(t, &errLocked{Name: "test"}, &errLocked{Name: "test"}, "exact match")
+	assert.ErrorIs(t, &errLocked{Name: "test"}, &errLocked{}, "generic match")
+	assert.False(t, errors.Is(&errLocked{}, &errLocked{Name: "test"}), "specific fail")
+	assert.False(t, errors.Is(&errLocked{}, &ErrBlocked{}), "kind mismatch")
+	assert.False(t, errors.Is(&ErrBlocked{}, &ErrDeprecated{}), "kind mismatch")
+	assert.Equal(t, CodeUnknown, (&errLocked{}).Code())
+	assert.Equal(t, CodeForbidden, (&ErrBlocked{}).Code())
+}
+
+var errBusy = NewCategory("busy", " is busy").WithCode(CodeUnavailable)
+
+func ExampleCategory() {
+	errLocked := NewCategory("locked", " is locked")
+	err := fmt.Errorf("update: %w", errLocked.Named("database"))
+	fmt.Println(err)
+	fmt.Println(errors.Is(err, errLocked))
+	fmt.Println(errors.Is(err, errLocked.Named("cache")))
+	// Output: update: database is locked
+	// true
+	// false
+}
+
+func TestCategory(t *testing.T) {
+	assert.Equal(t, "busy", errBusy.Error())
+	assert.Equal(t, "busy", errBusy.Named("").Error())
+	assert.Equal(t, name+" is busy", errBusy.Named(name).Error())
+	assert.ErrorIs(t, errBusy.Named("test"), errBusy.Named("test"), "exact match")
+	assert.ErrorIs(t, errBusy.Named("test"), errBusy.Named(""), "generic match")
+	assert.ErrorIs(t, errBusy.Named("test"), errBusy, "category match")
+	assert.False(t, errors.Is(errBusy.Named(""), errBusy.Named("test")), "specific fail")
+	assert.False(t, errors.Is(errBusy, errBusy.Named("test")), "specific fail")
+	other := NewCategory("busy", " is busy")
+	assert.False(t, errors.Is(errBusy.Named("test"), other), "category mismatch")
+	assert.False(t, errors.Is(errBusy.Named("test"), other.Named("test")), "category mismatch")
+	assert.Equal(t, CodeUnavailable, errBusy.Code())
+	assert.Equal(t, CodeUnavailable, CodeOf(fmt.Errorf("wrapped: %w", errBusy.Named("test"))))
+	assert.Equal(t, CodeUnknown, other.Named("test").Code())
+}
@@ -0,0 +1,205 @@
+package msg
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+
+	"github.com/madkins23/go-utils/log"
+)
+
+var _ error = &PanicError{}
+
+// PanicError is an error created from a recovered panic.
+// It carries the panic value and the stack trace at the point of the panic.
+type PanicError struct {
+	// Value passed to panic().
+	Value interface{}
+	stack []uintptr
+}
+
+// newPanicError returns a new PanicError with a stack trace.
+// Must be called directly from the deferred function that called recover().
+func newPanicError(value interface{}) *PanicError {
+	pcs := make([]uintptr, stackDepth)
+	// Skip runtime.Callers, newPanicError, the deferred function, and runtime.gopanic.
+	n := runtime.Callers(4, pcs)
+	return &PanicError{
+		Value: value,
+		stack: pcs[:n],
+	}
+}
+
+// Error implements the predefined error interface.
+func (pe *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", pe.Value)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (pe *PanicError) Unwrap() error {
+	if err, ok := pe.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// Code implements the Coder interface.
+func (pe *PanicError) Code() Code {
+	return CodeInternal
+}
+
+// StackTrace returns the stack trace captured when the panic was recovered
+// starting with the function that panicked.
+func (pe *PanicError) StackTrace() []runtime.Frame {
+	return frames(pe.stack)
+}
+
+// Format implements the fmt.Formatter interface.
+// The %s and %v verbs print the error message.
+// The %+v verb adds the stack trace.
+func (pe *PanicError) Format(state fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if state.Flag('+') {
+			_, _ = io.WriteString(state, pe.Error())
+			writeFrames(state, pe.StackTrace())
+			return
+		}
+		fallthrough
+	case 's':
+		_, _ = io.WriteString(state, pe.Error())
+	case 'q':
+		_, _ = fmt.Fprintf(state, "%q", pe.Error())
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////
+
+// PanicHandler is a function that reports a recovered panic.
+type PanicHandler func(err *PanicError)
+
+var (
+	panicLock    sync.RWMutex
+	panicHandler PanicHandler = LogPanic
+)
+
+// LogPanic is the default PanicHandler.
+// It logs the panic and its stack trace at error level using the log package.
+func LogPanic(err *PanicError) {
+	log.Error().Err(err).Str("stack", fmt.Sprintf("%+v", err)).Msg("Recovered panic")
+}
+
+// SetPanicHandler sets the handler used to report recovered panics.
+// A nil handler restores the default LogPanic handler.
+// Returns the previous handler.
+func SetPanicHandler(handler PanicHandler) PanicHandler {
+	if handler == nil {
+		handler = LogPanic
+	}
+	panicLock.Lock()
+	defer panicLock.Unlock()
+	previous := panicHandler
+	panicHandler = handler
+	return previous
+}
+
+// reportPanic passes a recovered panic to the current handler.
+func reportPanic(err *PanicError) {
+	panicLock.RLock()
+	handler := panicHandler
+	panicLock.RUnlock()
+	handler(err)
+}
+
+// Recover converts a panic into a PanicError.
+// Recover must be deferred directly, not called from another deferred function:
+//
+//	func doSomething() (err error) {
+//	    defer msg.Recover(&err)
+//	    ...
+//	}
+//
+// If errp is not nil the PanicError is stored in it, joined with any error already there.
+// If errp is nil the PanicError is reported to the panic handler (see SetPanicHandler).
+// Does nothing if there is no panic.
+func Recover(errp *error) {
+	if value := recover(); value != nil {
+		recovered(errp, newPanicError(value))
+	}
+}
+
+func recovered(errp *error, err *PanicError) {
+	if errp == nil {
+		reportPanic(err)
+	} else if *errp == nil {
+		*errp = err
+	} else {
+		var collector Collector
+		collector.Add(*errp)
+		collector.Add(err)
+		*errp = collector.Err()
+	}
+}
+
+// SafeGo runs the function in a new goroutine.
+// Any panic in the function is recovered and reported to the panic handler
+// instead of crashing the process.
+func SafeGo(fn func()) {
+	go func() {
+		defer Recover(nil)
+		fn()
+	}()
+}
+
+//////////////////////////////////////////////////////////////////////////
+
+// SafeGroup runs a group of functions in separate goroutines and collects their errors.
+// Panics in any function are recovered and collected as PanicError objects
+// instead of crashing the process.
+// The zero value is a valid SafeGroup without a context.
+type SafeGroup struct {
+	wait      sync.WaitGroup
+	collector Collector
+	cancel    context.CancelFunc
+}
+
+// NewSafeGroup returns a new SafeGroup and a context derived from the specified context.
+// The derived context is canceled the first time a function returns an error or panics
+// or when Wait returns, whichever occurs first.
+func NewSafeGroup(ctx context.Context) (*SafeGroup, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	return &SafeGroup{cancel: cancel}, ctx
+}
+
+// Go runs the function in a new goroutine.
+func (g *SafeGroup) Go(fn func() error) {
+	g.wait.Add(1)
+	go func() {
+		defer g.wait.Done()
+		if err := g.run(fn); err != nil {
+			g.collector.Add(err)
+			if g.cancel != nil {
+				g.cancel()
+			}
+		}
+	}()
+}
+
+// run the function, converting any panic into an error.
+func (g *SafeGroup) run(fn func() error) (err error) {
+	defer Recover(&err)
+	return fn()
+}
+
+// Wait blocks until all functions have returned.
+// Returns nil if no function returned an error or panicked,
+// otherwise returns a MultiError containing all errors.
+func (g *SafeGroup) Wait() error {
+	g.wait.Wait()
+	if g.cancel != nil {
+		g.cancel()
+	}
+	return g.collector.Err()
+}
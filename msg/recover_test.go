@@ -0,0 +1,138 @@
+package msg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/madkins23/go-utils/log"
+)
+
+func ExampleRecover() {
+	divide := func(a, b int) (result int, err error) {
+		defer Recover(&err)
+		return a / b, nil
+	}
+	_, err := divide(1, 0)
+	fmt.Println(err)
+	fmt.Println(CodeOf(err))
+	// Output: panic: runtime error: integer divide by zero
+	// Internal
+}
+
+func panicky(value interface{}) (err error) {
+	defer Recover(&err)
+	panic(value)
+}
+
+func TestRecover(t *testing.T) {
+	err := panicky(msg)
+	var pe *PanicError
+	require.ErrorAs(t, err, &pe)
+	assert.Equal(t, msg, pe.Value)
+	assert.Equal(t, "panic: "+msg, err.Error())
+	assert.Nil(t, pe.Unwrap())
+	frames := StackTrace(err)
+	require.NotEmpty(t, frames)
+	assert.True(t, strings.HasSuffix(frames[0].Function, "msg.panicky"), frames[0].Function)
+	assert.Contains(t, fmt.Sprintf("%+v", err), "msg.panicky")
+
+	cause := errors.New("cause")
+	err = panicky(cause)
+	assert.ErrorIs(t, err, cause)
+}
+
+func TestRecover_existing(t *testing.T) {
+	cause := errors.New("cause")
+	fn := func() (err error) {
+		defer Recover(&err)
+		defer func() {
+			err = cause
+		}()
+		panic(msg)
+	}
+	err := fn()
+	assert.ErrorIs(t, err, cause)
+	var pe *PanicError
+	assert.ErrorAs(t, err, &pe)
+}
+
+func TestRecover_noPanic(t *testing.T) {
+	fn := func() (err error) {
+		defer Recover(&err)
+		return nil
+	}
+	assert.NoError(t, fn())
+}
+
+func TestSafeGo(t *testing.T) {
+	reported := make(chan *PanicError)
+	previous := SetPanicHandler(func(err *PanicError) {
+		reported <- err
+	})
+	defer SetPanicHandler(previous)
+	SafeGo(func() {
+		panic(msg)
+	})
+	err := <-reported
+	assert.Equal(t, msg, err.Value)
+}
+
+func TestLogPanic(t *testing.T) {
+	var buffer bytes.Buffer
+	previous := *log.Logger()
+	log.SetLogger(zerolog.New(&buffer))
+	defer log.SetLogger(previous)
+	LogPanic(panicky(msg).(*PanicError))
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &record))
+	assert.Equal(t, "error", record["level"])
+	assert.Equal(t, "panic: "+msg, record["error"])
+	assert.Contains(t, record["stack"], "msg.panicky")
+}
+
+func TestSafeGroup(t *testing.T) {
+	var group SafeGroup
+	for i := 0; i < 5; i++ {
+		group.Go(func() error {
+			return nil
+		})
+	}
+	assert.NoError(t, group.Wait())
+
+	cause := errors.New("cause")
+	group.Go(func() error {
+		return cause
+	})
+	group.Go(func() error {
+		panic(msg)
+	})
+	err := group.Wait()
+	assert.ErrorIs(t, err, cause)
+	var pe *PanicError
+	require.ErrorAs(t, err, &pe)
+	assert.Equal(t, msg, pe.Value)
+}
+
+func TestNewSafeGroup(t *testing.T) {
+	group, ctx := NewSafeGroup(context.Background())
+	group.Go(func() error {
+		<-ctx.Done()
+		return nil
+	})
+	group.Go(func() error {
+		panic(msg)
+	})
+	err := group.Wait()
+	var pe *PanicError
+	assert.ErrorAs(t, err, &pe)
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+}
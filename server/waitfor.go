@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/madkins23/go-utils/clock"
 	"github.com/madkins23/go-utils/msg"
 )
 
@@ -32,10 +33,19 @@ const ErrServerNotReady msg.ConstError = "server not ready"
 // If the server does not properly respond within the timeout an error is returned.
 // A nil error return signifies the server is ready.
 func WaitFor(url string, timeout time.Duration) error {
+	return WaitForWithClock(url, timeout, clock.Default)
+}
+
+// WaitForWithClock pings server until it is actively serving requests for the specified url.
+// Time is measured using the specified clock which may be a clock.Fake object in tests.
+// If the server does not properly respond within the timeout an error is returned.
+// A nil error return signifies the server is ready.
+func WaitForWithClock(url string, timeout time.Duration, clk clock.Clock) error {
 	const loopWait = 25 * time.Millisecond
-	tooLate := time.Now().Add(timeout)
-	for time.Now().Before(tooLate) {
-		time.Sleep(loopWait)
+	clk = clock.Or(clk)
+	tooLate := clk.Now().Add(timeout)
+	for clk.Now().Before(tooLate) {
+		clk.Sleep(loopWait)
 		if err := IsReady(url); err == nil {
 			return nil
 		}
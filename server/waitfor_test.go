@@ -10,6 +10,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/madkins23/go-utils/clock"
 )
 
 const (
@@ -47,6 +49,42 @@ func TestWaitFor_Ping(t *testing.T) {
 	server.Close()
 }
 
+func TestWaitForWithClock_NoService(t *testing.T) {
+	fake := clock.NewFake(time.Time{})
+	result := make(chan error)
+	go func() {
+		result <- WaitForWithClock(badURL, 250*time.Millisecond, fake)
+	}()
+	advances := 0
+	for {
+		select {
+		case err := <-result:
+			assert.ErrorIs(t, err, ErrServerNotReady)
+			assert.Equal(t, 10, advances, "250ms at 25ms per loop")
+			return
+		default:
+		}
+		if fake.Waiters() > 0 {
+			fake.Advance(25 * time.Millisecond)
+			advances++
+		}
+	}
+}
+
+func TestWaitForWithClock_Ping(t *testing.T) {
+	server := runPingService()
+	require.NotNil(t, server)
+	defer server.Close()
+	fake := clock.NewFake(time.Time{})
+	result := make(chan error)
+	go func() {
+		result <- WaitForWithClock(server.URL, time.Second, fake)
+	}()
+	fake.BlockUntil(1)
+	fake.Advance(25 * time.Millisecond)
+	assert.NoError(t, <-result)
+}
+
 //////////////////////////////////////////////////////////////////////////
 
 func ExampleWaitFor() {